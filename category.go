@@ -0,0 +1,104 @@
+package wizlib
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// CategoryMembers returns the titles of all pages in a category, following API continuation.
+func (s *WikiService) CategoryMembers(category string) ([]string, error) {
+	if !strings.HasPrefix(strings.ToLower(category), "category:") {
+		category = "Category:" + category
+	}
+
+	params := url.Values{}
+	params.Set("action", "query")
+	params.Set("list", "categorymembers")
+	params.Set("cmtitle", category)
+	params.Set("cmtype", "page")
+	params.Set("cmlimit", "500")
+
+	var titles []string
+	for {
+		body, err := s.query(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var response struct {
+			Continue map[string]string `json:"continue"`
+			Error    *APIError         `json:"error"`
+			Query    struct {
+				CategoryMembers []struct {
+					Title string `json:"title"`
+				} `json:"categorymembers"`
+			} `json:"query"`
+		}
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, err
+		}
+		if response.Error != nil {
+			return nil, response.Error
+		}
+
+		for _, member := range response.Query.CategoryMembers {
+			titles = append(titles, member.Title)
+		}
+
+		cont, ok := response.Continue["cmcontinue"]
+		if !ok {
+			return titles, nil
+		}
+		params.Set("cmcontinue", cont)
+	}
+}
+
+// LatestRevisions returns the current revision ID of each page, keyed by normalized title.
+// Missing pages are left out of the result.
+func (s *WikiService) LatestRevisions(titles []string) (map[string]int64, error) {
+	revisions := make(map[string]int64, len(titles))
+
+	// The API accepts up to 50 titles per request.
+	for start := 0; start < len(titles); start += 50 {
+		end := start + 50
+		if end > len(titles) {
+			end = len(titles)
+		}
+
+		params := url.Values{}
+		params.Set("action", "query")
+		params.Set("prop", "info")
+		params.Set("titles", strings.Join(titles[start:end], "|"))
+
+		body, err := s.query(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var response struct {
+			Error *APIError `json:"error"`
+			Query struct {
+				Pages []struct {
+					Title     string `json:"title"`
+					LastRevID int64  `json:"lastrevid"`
+					Missing   bool   `json:"missing"`
+				} `json:"pages"`
+			} `json:"query"`
+		}
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, err
+		}
+		if response.Error != nil {
+			return nil, response.Error
+		}
+
+		for _, page := range response.Query.Pages {
+			if !page.Missing {
+				revisions[normalizeTitle(page.Title)] = page.LastRevID
+			}
+		}
+	}
+
+	return revisions, nil
+}
@@ -0,0 +1,365 @@
+package wizlib
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// CrawlProgress reports the state of a crawl after a page was processed.
+type CrawlProgress struct {
+	Title   string `json:"title"`
+	Err     error  `json:"-"`
+	Visited int    `json:"visited"`
+	Queued  int    `json:"queued"`
+	Failed  int    `json:"failed"`
+}
+
+// crawlItem is a page waiting to be crawled, with its distance from the seeds.
+type crawlItem struct {
+	Title string `json:"title"`
+	Depth int    `json:"depth"`
+}
+
+// crawlerState is persisted between runs.
+type crawlerState struct {
+	Visited []string    `json:"visited"`
+	Queue   []crawlItem `json:"queue"`
+}
+
+// Crawler fetches pages through a WikiService with a pool of workers, starting from seed pages and
+// categories and following links. Pages are fetched with GetWikiText, so fetch hooks such as an
+// attached SearchIndex see every page. Visited pages and the queue are saved to StatePath, so a
+// crawler created again with the same path resumes where the last run stopped.
+type Crawler struct {
+	Service *WikiService
+	// Workers is the number of pages fetched in parallel.
+	Workers int
+	// PerHost and Interval limit the crawler's pages in flight and space out their fetches.
+	// They apply to the crawler only, not to other users of the service's client.
+	PerHost  int
+	Interval time.Duration
+	// FollowLinks expands the crawl to pages linked from crawled pages, up to MaxDepth links from a seed.
+	FollowLinks bool
+	MaxDepth    int
+	// MaxPages stops the crawl after this many pages; zero crawls until the queue is empty.
+	MaxPages int
+	// Filter decides whether a linked page is crawled; by default every linked page is.
+	Filter    func(title string) bool
+	OnPage    func(page WikiResponse)
+	Progress  func(CrawlProgress)
+	StatePath string
+	// SaveEvery is the number of pages between state saves.
+	SaveEvery int
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	visited  map[string]bool
+	queued   map[string]bool
+	queue    []crawlItem
+	failed   []crawlItem
+	inflight int
+	paused   bool
+	unsaved  int
+	fetched  int
+
+	limitMu   sync.Mutex
+	nextFetch time.Time
+}
+
+// NewCrawler creates a new instance of Crawler, resuming from the state at statePath if it exists.
+func NewCrawler(service *WikiService, statePath string) (*Crawler, error) {
+	c := &Crawler{
+		Service:   service,
+		Workers:   4,
+		PerHost:   2,
+		Interval:  200 * time.Millisecond,
+		MaxDepth:  1,
+		StatePath: statePath,
+		SaveEvery: 50,
+		visited:   make(map[string]bool),
+		queued:    make(map[string]bool),
+	}
+	c.cond = sync.NewCond(&c.mu)
+
+	if statePath == "" {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state crawlerState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	for _, title := range state.Visited {
+		c.visited[title] = true
+	}
+	for _, item := range state.Queue {
+		c.enqueue(item)
+	}
+
+	return c, nil
+}
+
+// Add queues pages to crawl.
+func (c *Crawler) Add(titles ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, title := range titles {
+		c.enqueue(crawlItem{Title: normalizeTitle(title)})
+	}
+	c.cond.Broadcast()
+}
+
+// AddCategory queues every page in a category.
+func (c *Crawler) AddCategory(category string) error {
+	titles, err := c.Service.CategoryMembers(category)
+	if err != nil {
+		return err
+	}
+	c.Add(titles...)
+	return nil
+}
+
+// Pause stops workers from starting new pages; pages already being fetched finish.
+func (c *Crawler) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.paused = true
+}
+
+// Resume lets a paused crawl continue.
+func (c *Crawler) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.paused = false
+	c.cond.Broadcast()
+}
+
+// Visited reports whether a page has been crawled.
+func (c *Crawler) Visited(title string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.visited[normalizeTitle(title)]
+}
+
+// Run crawls until the queue is empty, MaxPages is reached or ctx is cancelled, then saves the state.
+// Pages that failed with an error other than not found stay queued for the next run.
+func (c *Crawler) Run(ctx context.Context) error {
+	var slots chan struct{}
+	if c.PerHost > 0 {
+		slots = make(chan struct{}, c.PerHost)
+	}
+
+	// Wake waiting workers when the context is cancelled.
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.mu.Lock()
+			c.cond.Broadcast()
+			c.mu.Unlock()
+		case <-stop:
+		}
+	}()
+
+	workers := c.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.work(ctx, slots)
+		}()
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	for _, item := range c.failed {
+		c.enqueue(item)
+	}
+	c.failed = nil
+	c.mu.Unlock()
+
+	if err := c.Save(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// Save writes the visited pages and the queue to StatePath.
+func (c *Crawler) Save() error {
+	if c.StatePath == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	state := crawlerState{
+		Visited: sortedKeys(c.visited),
+		Queue:   append(append([]crawlItem(nil), c.queue...), c.failed...),
+	}
+	c.unsaved = 0
+	c.mu.Unlock()
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(c.StatePath, data)
+}
+
+// work fetches pages until there are none left, holding one of slots, if any, during each fetch.
+func (c *Crawler) work(ctx context.Context, slots chan struct{}) {
+	for {
+		item, ok := c.next(ctx)
+		if !ok {
+			return
+		}
+
+		var page WikiResponse
+		err := c.throttle(ctx, slots)
+		if err == nil {
+			page, err = c.Service.GetWikiText(item.Title)
+			if slots != nil {
+				<-slots
+			}
+		}
+		if err == nil && c.OnPage != nil {
+			c.OnPage(page)
+		}
+
+		progress, save := c.done(item, page, err)
+		if save {
+			// A failed save is retried at the next interval and at the end of the run.
+			c.Save()
+		}
+		if c.Progress != nil {
+			c.Progress(progress)
+		}
+	}
+}
+
+// throttle waits for a free slot and until Interval has passed since the previous fetch started.
+// When it returns nil, the caller holds a slot and must release it.
+func (c *Crawler) throttle(ctx context.Context, slots chan struct{}) error {
+	if slots != nil {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	c.limitMu.Lock()
+	now := time.Now()
+	start := c.nextFetch
+	if start.Before(now) {
+		start = now
+	}
+	c.nextFetch = start.Add(c.Interval)
+	c.limitMu.Unlock()
+
+	timer := time.NewTimer(time.Until(start))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		if slots != nil {
+			<-slots
+		}
+		return ctx.Err()
+	}
+}
+
+// next waits for a page to crawl, reporting false when the crawl is over.
+func (c *Crawler) next(ctx context.Context) (crawlItem, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for {
+		if ctx.Err() != nil {
+			return crawlItem{}, false
+		}
+		limited := c.MaxPages > 0 && c.fetched+c.inflight >= c.MaxPages
+		if limited && c.inflight == 0 || len(c.queue) == 0 && c.inflight == 0 {
+			c.cond.Broadcast()
+			return crawlItem{}, false
+		}
+
+		if !c.paused && !limited && len(c.queue) > 0 {
+			item := c.queue[0]
+			c.queue = c.queue[1:]
+			delete(c.queued, item.Title)
+			if c.visited[item.Title] {
+				continue
+			}
+			c.inflight++
+			return item, true
+		}
+
+		c.cond.Wait()
+	}
+}
+
+// done records the outcome of fetching a page and queues its links.
+func (c *Crawler) done(item crawlItem, page WikiResponse, err error) (CrawlProgress, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.cond.Broadcast()
+
+	c.inflight--
+	c.fetched++
+
+	switch {
+	case err == nil || errors.Is(err, ErrPageNotFound):
+		c.visited[item.Title] = true
+		if err == nil && c.FollowLinks && item.Depth < c.MaxDepth {
+			for _, link := range linkTargets(page.Parse.Content) {
+				if c.Filter == nil || c.Filter(link) {
+					c.enqueue(crawlItem{Title: link, Depth: item.Depth + 1})
+				}
+			}
+		}
+	default:
+		c.failed = append(c.failed, item)
+	}
+
+	c.unsaved++
+	save := c.SaveEvery > 0 && c.unsaved >= c.SaveEvery
+
+	return CrawlProgress{
+		Title:   item.Title,
+		Err:     err,
+		Visited: len(c.visited),
+		Queued:  len(c.queue),
+		Failed:  len(c.failed),
+	}, save
+}
+
+// enqueue adds a page to the queue unless it was visited or is already queued; the caller must hold mu.
+func (c *Crawler) enqueue(item crawlItem) {
+	if item.Title == "" || c.visited[item.Title] || c.queued[item.Title] {
+		return
+	}
+	c.queued[item.Title] = true
+	c.queue = append(c.queue, item)
+}
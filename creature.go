@@ -0,0 +1,293 @@
+package wizlib
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SchoolModifier represents a school boost or resistance of a creature.
+type SchoolModifier struct {
+	School  string `json:"school"`
+	Percent int    `json:"percent,omitempty"`
+}
+
+// CreatureDrop represents an item a creature can drop.
+type CreatureDrop struct {
+	Item     string `json:"item"`
+	Category string `json:"category,omitempty"`
+	Chance   string `json:"chance,omitempty"`
+}
+
+// Creature represents a creature or boss decoded from a creature infobox and its drop sections.
+type Creature struct {
+	Name       string            `json:"name"`
+	Rank       int               `json:"rank"`
+	Health     int               `json:"health"`
+	School     string            `json:"school"`
+	Class      string            `json:"class,omitempty"`
+	Boss       bool              `json:"boss"`
+	Boosts     []SchoolModifier  `json:"boosts,omitempty"`
+	Resists    []SchoolModifier  `json:"resists,omitempty"`
+	StartPips  int               `json:"start_pips"`
+	PipChance  int               `json:"pip_chance,omitempty"`
+	PowerPips  bool              `json:"power_pips"`
+	ShadowPips bool              `json:"shadow_pips,omitempty"`
+	Cheats     string            `json:"cheats,omitempty"`
+	Minions    []string          `json:"minions,omitempty"`
+	Location   string            `json:"location,omitempty"`
+	World      string            `json:"world,omitempty"`
+	Drops      []CreatureDrop    `json:"drops,omitempty"`
+	Infobox    map[string]string `json:"infobox"`
+}
+
+// creatureInfoboxNames lists the template names used for creature pages.
+var creatureInfoboxNames = []string{"CreatureInfobox", "Creature Infobox", "Infobox Creature", "BossInfobox", "MinionInfobox"}
+
+// GetCreature retrieves a creature page and decodes its infobox and drop sections.
+func (s *WikiService) GetCreature(title string) (*Creature, error) {
+	names, err := s.infoboxNames("creature")
+	if err != nil {
+		return nil, err
+	}
+	wiki, err := s.GetWikiText(title)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeCreature(wiki.Parse.Title, wiki.Parse.Content, names)
+}
+
+// DecodeCreature builds a Creature from the wikitext of a creature page of the Wizard101 Central wiki.
+func DecodeCreature(title, wikiText string) (*Creature, error) {
+	return decodeCreature(title, wikiText, creatureInfoboxNames)
+}
+
+// decodeCreature builds a Creature from the wikitext of a page whose infobox is one of names.
+func decodeCreature(title, wikiText string, names []string) (*Creature, error) {
+	t, ok := FindTemplate(wikiText, names...)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", title, ErrNoInfobox)
+	}
+	f := newInfoboxFields(t)
+
+	creature := &Creature{
+		Name:      f.text("name"),
+		Rank:      f.int("rank"),
+		Health:    f.int("health", "hp", "maxhealth"),
+		School:    parseSchool(f.raw("school")),
+		Class:     f.text("class", "classification", "type", "creaturetype"),
+		Boosts:    parseSchoolModifiers(f.text("boost", "boosts", "weakness", "weaknesses")),
+		Resists:   parseSchoolModifiers(f.text("resist", "resists", "resistance", "resistances")),
+		StartPips: f.int("startpips", "startingpips", "pips", "pipsatstart"),
+		PipChance: f.int("powerpipchance", "pipchance"),
+		Cheats:    f.text("cheats", "cheat"),
+		Minions:   f.list("minions", "minion"),
+		Location:  f.text("location", "area", "dungeon"),
+		World:     f.text("world"),
+		Infobox:   t.Map(),
+	}
+	if creature.Name == "" {
+		creature.Name = stripNamespace(title)
+	}
+
+	pips := strings.ToLower(f.text("powerpips", "pipbehavior", "piptype"))
+	creature.PowerPips = parseBool(pips) || strings.Contains(pips, "power") || creature.PipChance > 0
+	creature.ShadowPips = f.bool("shadowpips", "shadowpip")
+
+	rank := strings.ToLower(f.text("rank") + " " + creature.Class)
+	creature.Boss = strings.Contains(rank, "boss") || f.bool("boss")
+
+	for _, section := range ParseSections(wikiText) {
+		lower := strings.ToLower(section.Title)
+		switch {
+		case strings.Contains(lower, "cheat") && creature.Cheats == "":
+			creature.Cheats = RenderPlainText(section.Body)
+		case strings.Contains(lower, "minion") && len(creature.Minions) == 0:
+			creature.Minions = linkTargets(section.Body)
+		}
+	}
+
+	creature.Drops = dedupeDrops(append(decodeInfoboxDrops(f), decodeDropSections(wikiText)...))
+
+	return creature, nil
+}
+
+var modifierRegex = regexp.MustCompile(`(?i)(\d+)\s*%\s*([a-z]+)|([a-z]+)\s*(?:\(?\s*(\d+)\s*%\s*\)?)?`)
+
+// parseSchoolModifiers parses values such as "Fire 25%, Ice" or "25% Fire" into school modifiers.
+func parseSchoolModifiers(text string) []SchoolModifier {
+	var modifiers []SchoolModifier
+	for _, m := range modifierRegex.FindAllStringSubmatch(text, -1) {
+		name, percent := m[3], m[4]
+		if m[2] != "" {
+			name, percent = m[2], m[1]
+		}
+		school := parseSchool(name)
+		if !isSchool(school) {
+			continue
+		}
+		modifier := SchoolModifier{School: school}
+		if percent != "" {
+			modifier.Percent, _ = parseInt(percent)
+		}
+		modifiers = append(modifiers, modifier)
+	}
+	return modifiers
+}
+
+// dropCategories maps normalized infobox keys listing drops to their category.
+var dropCategories = map[string]string{
+	"drops":         "",
+	"itemdrops":     "",
+	"hats":          "Hat",
+	"robes":         "Robe",
+	"boots":         "Boots",
+	"wands":         "Wand",
+	"athames":       "Athame",
+	"amulets":       "Amulet",
+	"rings":         "Ring",
+	"decks":         "Deck",
+	"jewels":        "Jewel",
+	"pets":          "Pet",
+	"mounts":        "Mount",
+	"housing":       "Housing",
+	"housingitems":  "Housing",
+	"reagents":      "Reagent",
+	"snacks":        "Snack",
+	"treasurecards": "Treasure Card",
+	"spells":        "Spell",
+	"recipes":       "Recipe",
+	"seeds":         "Seed",
+}
+
+// decodeInfoboxDrops reads drops listed in infobox parameters such as "hats" or "reagents".
+func decodeInfoboxDrops(f infoboxFields) []CreatureDrop {
+	var drops []CreatureDrop
+	for key, category := range dropCategories {
+		if value, ok := f[key]; ok {
+			drops = append(drops, parseDropList(value, category)...)
+		}
+	}
+	sortDrops(drops)
+	return drops
+}
+
+// decodeDropSections reads drops from "Drops" sections, using sub-headings and table columns as categories.
+func decodeDropSections(wikiText string) []CreatureDrop {
+	var drops []CreatureDrop
+	inDrops, dropsLevel := false, 0
+
+	for _, section := range ParseSections(wikiText) {
+		if section.Level == 0 {
+			continue
+		}
+		switch {
+		case strings.Contains(strings.ToLower(section.Title), "drop"):
+			inDrops, dropsLevel = true, section.Level
+		case inDrops && section.Level <= dropsLevel:
+			inDrops = false
+		}
+		if !inDrops {
+			continue
+		}
+
+		category := ""
+		if section.Level > dropsLevel {
+			category = singular(section.Title)
+		}
+
+		for _, table := range ParseWikiTables(section.Body) {
+			for _, row := range table.Rows {
+				for i, cell := range row {
+					cellCategory := category
+					if i < len(table.Headers) && !table.numbered {
+						cellCategory = singular(table.Headers[i])
+					}
+					drops = append(drops, parseDropList(cell.Raw, cellCategory)...)
+				}
+			}
+		}
+
+		// Lists inside tables were read with their column's category above.
+		depth := 0
+		for _, line := range strings.Split(section.Body, "\n") {
+			line = strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(line, "{|"):
+				depth++
+			case strings.HasPrefix(line, "|}"):
+				if depth > 0 {
+					depth--
+				}
+			case depth == 0 && (strings.HasPrefix(line, "*") || strings.HasPrefix(line, "#")):
+				drops = append(drops, parseDropList(line, category)...)
+			}
+		}
+	}
+
+	return drops
+}
+
+var chanceRegex = regexp.MustCompile(`(?i)\(([^)]*(?:%|rare|common|uncommon|ultra)[^)]*)\)`)
+
+// parseDropList turns a list of linked or plain item names into drops.
+func parseDropList(value, category string) []CreatureDrop {
+	var drops []CreatureDrop
+	for _, line := range strings.Split(strings.ReplaceAll(breakRegex.ReplaceAllString(value, "\n"), "*", "\n"), "\n") {
+		chance := ""
+		if m := chanceRegex.FindStringSubmatch(line); m != nil {
+			chance = strings.TrimSpace(m[1])
+		}
+
+		targets := linkTargets(line)
+		if len(targets) == 0 {
+			for _, name := range splitList(chanceRegex.ReplaceAllString(line, "")) {
+				drops = append(drops, CreatureDrop{Item: name, Category: category, Chance: chance})
+			}
+			continue
+		}
+		for _, target := range targets {
+			drops = append(drops, CreatureDrop{Item: target, Category: category, Chance: chance})
+		}
+	}
+	return drops
+}
+
+// dedupeDrops removes repeated drops of the same item and category, keeping the first.
+func dedupeDrops(drops []CreatureDrop) []CreatureDrop {
+	kept := drops[:0]
+	seen := make(map[[2]string]bool, len(drops))
+	for _, drop := range drops {
+		key := [2]string{normalizeTitle(drop.Item), drop.Category}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		kept = append(kept, drop)
+	}
+	return kept
+}
+
+// sortDrops orders drops by category and item so that decoding is deterministic.
+func sortDrops(drops []CreatureDrop) {
+	sort.Slice(drops, func(i, j int) bool {
+		if drops[i].Category != drops[j].Category {
+			return drops[i].Category < drops[j].Category
+		}
+		return drops[i].Item < drops[j].Item
+	})
+}
+
+// singular turns a heading such as "Hats" into the category "Hat".
+func singular(heading string) string {
+	heading = strings.TrimSpace(heading)
+	switch {
+	case strings.HasSuffix(heading, "Boots"), strings.HasSuffix(heading, "Housing"):
+		return heading
+	case strings.HasSuffix(heading, "s") && !strings.HasSuffix(heading, "ss"):
+		return strings.TrimSuffix(heading, "s")
+	}
+	return heading
+}
@@ -0,0 +1,34 @@
+package wizlib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeCreatureTableOfBullets(t *testing.T) {
+	text := "{{CreatureInfobox\n|name = Boss\n|hats = [[Hat A]]\n}}\n" +
+		"== Drops ==\n{|\n! Hats !! Robes\n|-\n|\n* [[Hat A]]\n|\n* [[Robe B]]\n|}\n* [[Wand C]]\n"
+	creature, err := DecodeCreature("Boss", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CreatureDrop{{Item: "Hat A", Category: "Hat"}, {Item: "Robe B", Category: "Robe"}, {Item: "Wand C"}}
+	if !reflect.DeepEqual(creature.Drops, want) {
+		t.Errorf("Drops = %v, want %v", creature.Drops, want)
+	}
+}
+
+func TestParseSchoolModifiers(t *testing.T) {
+	tests := []struct {
+		text string
+		want []SchoolModifier
+	}{
+		{"Fire 25%, Ice", []SchoolModifier{{School: "Fire", Percent: 25}, {School: "Ice"}}},
+		{"25% Fire, 10% Storm", []SchoolModifier{{School: "Fire", Percent: 25}, {School: "Storm", Percent: 10}}},
+	}
+	for _, tt := range tests {
+		if got := parseSchoolModifiers(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSchoolModifiers(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
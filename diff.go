@@ -0,0 +1,271 @@
+package wizlib
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// FieldChangeKind describes how a template field changed between revisions.
+type FieldChangeKind string
+
+const (
+	FieldAdded    FieldChangeKind = "added"
+	FieldRemoved  FieldChangeKind = "removed"
+	FieldModified FieldChangeKind = "modified"
+)
+
+// FieldChange represents a change to one template parameter.
+// Key is empty when a template without named parameters was added or removed.
+type FieldChange struct {
+	Template string          `json:"template"`
+	Key      string          `json:"key,omitempty"`
+	Kind     FieldChangeKind `json:"kind"`
+	Old      string          `json:"old,omitempty"`
+	New      string          `json:"new,omitempty"`
+}
+
+// RevisionDiff lists the template field changes between two revisions of a page.
+type RevisionDiff struct {
+	Title   string        `json:"title"`
+	OldRev  int64         `json:"old_rev"`
+	NewRev  int64         `json:"new_rev"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// Markdown renders the diff as a changelog grouped by template.
+func (d *RevisionDiff) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## %s\n\nRevision %d → %d\n", d.Title, d.OldRev, d.NewRev)
+	if len(d.Changes) == 0 {
+		sb.WriteString("\nNo template fields changed.\n")
+		return sb.String()
+	}
+
+	template := ""
+	for i, c := range d.Changes {
+		if i == 0 || c.Template != template {
+			template = c.Template
+			fmt.Fprintf(&sb, "\n### %s\n\n", template)
+		}
+
+		switch {
+		case c.Key == "" && c.Kind == FieldAdded:
+			sb.WriteString("- Template added\n")
+		case c.Key == "" && c.Kind == FieldRemoved:
+			sb.WriteString("- Template removed\n")
+		case c.Kind == FieldAdded:
+			fmt.Fprintf(&sb, "- Added **%s**: %s\n", c.Key, changelogValue(c.New))
+		case c.Kind == FieldRemoved:
+			fmt.Fprintf(&sb, "- Removed **%s** (was %s)\n", c.Key, changelogValue(c.Old))
+		default:
+			fmt.Fprintf(&sb, "- **%s**: %s → %s\n", c.Key, changelogValue(c.Old), changelogValue(c.New))
+		}
+	}
+
+	return sb.String()
+}
+
+// changelogValue formats a raw value for the changelog.
+func changelogValue(value string) string {
+	text := plainValue(value)
+	if text == "" {
+		text = strings.TrimSpace(value)
+	}
+	return "`" + strings.ReplaceAll(text, "`", "'") + "`"
+}
+
+// DiffRevisions compares the templates of two revisions of a page field by field.
+func (s *WikiService) DiffRevisions(title string, oldRev, newRev int64) (*RevisionDiff, error) {
+	old, err := s.GetRevision(oldRev)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.GetRevision(newRev)
+	if err != nil {
+		return nil, err
+	}
+
+	// Revisions are matched by page ID, since the page may have been moved between them.
+	if old.Parse.PageID != current.Parse.PageID {
+		return nil, fmt.Errorf("revisions %d and %d belong to different pages", oldRev, newRev)
+	}
+	if key := normalizeTitle(title); normalizeTitle(old.Parse.Title) != key && normalizeTitle(current.Parse.Title) != key {
+		page, err := s.GetWikiText(title)
+		if err != nil {
+			return nil, err
+		}
+		if page.Parse.PageID != current.Parse.PageID {
+			return nil, fmt.Errorf("revision %d belongs to %s, not %s", newRev, current.Parse.Title, title)
+		}
+	}
+
+	return &RevisionDiff{
+		Title:   current.Parse.Title,
+		OldRev:  oldRev,
+		NewRev:  newRev,
+		Changes: DiffTemplates(ParseTemplates(old.Parse.Content), ParseTemplates(current.Parse.Content)),
+	}, nil
+}
+
+// GetRevision retrieves the wikitext of a specific revision through action=parse&oldid=.
+// Page sources only hold current revisions, so this needs the API and fails with ErrOffline without it.
+// Revisions never change, so they stay cached until they expire or are removed with InvalidateRevision.
+func (s *WikiService) GetRevision(revID int64) (WikiResponse, error) {
+	key := revisionCacheKey(revID)
+	if cached, ok := s.Cache.Get(key); ok {
+		if response, ok := cached.(WikiResponse); ok {
+			return response, nil
+		}
+	}
+	if s.Client == nil {
+		return WikiResponse{}, ErrOffline
+	}
+
+	params := url.Values{}
+	params.Set("action", "parse")
+	params.Set("oldid", strconv.FormatInt(revID, 10))
+	params.Set("prop", "wikitext|images")
+
+	body, err := s.query(params)
+	if err != nil {
+		return WikiResponse{}, err
+	}
+
+	var response struct {
+		WikiResponse
+		Error *APIError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return WikiResponse{}, err
+	}
+	if response.Error != nil {
+		return WikiResponse{}, response.Error
+	}
+
+	// Revisions are not tied to the page title, since invalidating the page does not change them.
+	s.Cache.SetEntry(key, "", response.WikiResponse, responseSize(response.WikiResponse), 0)
+	return response.WikiResponse, nil
+}
+
+// InvalidateRevision removes a cached revision.
+func (s *WikiService) InvalidateRevision(revID int64) {
+	s.Cache.Delete(revisionCacheKey(revID))
+}
+
+// revisionCacheKey returns the cache key of a revision; "#" cannot appear in page titles, so keys never collide.
+func revisionCacheKey(revID int64) string {
+	return "#rev" + strconv.FormatInt(revID, 10)
+}
+
+// DiffTemplates compares two lists of templates field by field. Templates are matched by name and
+// by their order among templates of the same name; keys are matched ignoring case and spacing.
+func DiffTemplates(old, current []Template) []FieldChange {
+	oldByName := groupTemplates(old)
+	newByName := groupTemplates(current)
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, list := range [][]Template{current, old} {
+		for _, t := range list {
+			if name := normalizeTitle(t.Name); !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	var changes []FieldChange
+	for _, name := range names {
+		a, b := oldByName[name], newByName[name]
+		for i := 0; i < len(a) || i < len(b); i++ {
+			label := name
+			if len(a) > 1 || len(b) > 1 {
+				label = fmt.Sprintf("%s #%d", name, i+1)
+			}
+
+			switch {
+			case i >= len(a):
+				changes = append(changes, templateChanges(label, b[i], FieldAdded)...)
+			case i >= len(b):
+				changes = append(changes, templateChanges(label, a[i], FieldRemoved)...)
+			default:
+				changes = append(changes, diffParams(label, a[i], b[i])...)
+			}
+		}
+	}
+
+	return changes
+}
+
+// groupTemplates groups templates by normalized name, keeping their order.
+func groupTemplates(templates []Template) map[string][]Template {
+	groups := make(map[string][]Template)
+	for _, t := range templates {
+		name := normalizeTitle(t.Name)
+		groups[name] = append(groups[name], t)
+	}
+	return groups
+}
+
+// templateChanges reports every parameter of a template that was added or removed as a whole.
+func templateChanges(label string, t Template, kind FieldChangeKind) []FieldChange {
+	if len(t.Params) == 0 {
+		return []FieldChange{{Template: label, Kind: kind}}
+	}
+
+	changes := make([]FieldChange, 0, len(t.Params))
+	for _, p := range t.Params {
+		c := FieldChange{Template: label, Key: strings.TrimSpace(p.Key), Kind: kind}
+		if kind == FieldAdded {
+			c.New = strings.TrimSpace(p.Value)
+		} else {
+			c.Old = strings.TrimSpace(p.Value)
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// diffParams compares the parameters of two versions of a template, in the order of the newer one.
+func diffParams(label string, old, current Template) []FieldChange {
+	oldValues := make(map[string]TemplateParam)
+	for _, p := range old.Params {
+		oldValues[normalizeKey(p.Key)] = p
+	}
+
+	var changes []FieldChange
+	seen := make(map[string]bool)
+	for _, p := range current.Params {
+		key := normalizeKey(p.Key)
+		seen[key] = true
+		value := strings.TrimSpace(p.Value)
+
+		prev, ok := oldValues[key]
+		switch {
+		case !ok && value != "":
+			changes = append(changes, FieldChange{Template: label, Key: strings.TrimSpace(p.Key), Kind: FieldAdded, New: value})
+		case ok && strings.TrimSpace(prev.Value) != value:
+			kind := FieldModified
+			if value == "" {
+				kind = FieldRemoved
+			} else if strings.TrimSpace(prev.Value) == "" {
+				kind = FieldAdded
+			}
+			changes = append(changes, FieldChange{Template: label, Key: strings.TrimSpace(p.Key), Kind: kind, Old: strings.TrimSpace(prev.Value), New: value})
+		}
+	}
+
+	for _, p := range old.Params {
+		key := normalizeKey(p.Key)
+		if seen[key] || strings.TrimSpace(p.Value) == "" {
+			continue
+		}
+		seen[key] = true
+		changes = append(changes, FieldChange{Template: label, Key: strings.TrimSpace(p.Key), Kind: FieldRemoved, Old: strings.TrimSpace(p.Value)})
+	}
+
+	return changes
+}
@@ -0,0 +1,375 @@
+package wizlib
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DropSource describes a creature that drops a given item.
+type DropSource struct {
+	Item     string `json:"item,omitempty"`
+	Creature string `json:"creature"`
+	Location string `json:"location,omitempty"`
+	World    string `json:"world,omitempty"`
+	Rank     int    `json:"rank"`
+	Boss     bool   `json:"boss"`
+	Category string `json:"category,omitempty"`
+	Chance   string `json:"chance,omitempty"`
+}
+
+// indexedCreature is the part of a creature kept in a DropIndex.
+type indexedCreature struct {
+	Title    string         `json:"title"`
+	RevID    int64          `json:"revid"`
+	Location string         `json:"location,omitempty"`
+	World    string         `json:"world,omitempty"`
+	Rank     int            `json:"rank"`
+	Boss     bool           `json:"boss"`
+	Drops    []CreatureDrop `json:"drops"`
+}
+
+// DropIndex answers which creatures drop an item, built from creature pages.
+type DropIndex struct {
+	mu        sync.RWMutex
+	creatures map[string]*indexedCreature
+	items     map[string]map[string]bool
+}
+
+// NewDropIndex creates a new, empty instance of DropIndex.
+func NewDropIndex() *DropIndex {
+	return &DropIndex{
+		creatures: make(map[string]*indexedCreature),
+		items:     make(map[string]map[string]bool),
+	}
+}
+
+// LoadDropIndex reads an index previously written with Save.
+func LoadDropIndex(path string) (*DropIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var creatures []*indexedCreature
+	if err := json.Unmarshal(data, &creatures); err != nil {
+		return nil, err
+	}
+
+	d := NewDropIndex()
+	for _, c := range creatures {
+		d.insert(c)
+	}
+
+	return d, nil
+}
+
+// Save writes the index to path.
+func (d *DropIndex) Save(path string) error {
+	d.mu.RLock()
+	creatures := make([]*indexedCreature, 0, len(d.creatures))
+	for _, c := range d.creatures {
+		creatures = append(creatures, c)
+	}
+	d.mu.RUnlock()
+
+	sort.Slice(creatures, func(i, j int) bool { return creatures[i].Title < creatures[j].Title })
+
+	data, err := json.Marshal(creatures)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, data)
+}
+
+// Len returns the number of indexed creatures.
+func (d *DropIndex) Len() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return len(d.creatures)
+}
+
+// AddCreature indexes the drops of a creature page at the given revision.
+func (d *DropIndex) AddCreature(title string, revID int64, creature *Creature) {
+	d.insert(&indexedCreature{
+		Title:    normalizeTitle(title),
+		RevID:    revID,
+		Location: creature.Location,
+		World:    creature.World,
+		Rank:     creature.Rank,
+		Boss:     creature.Boss,
+		Drops:    creature.Drops,
+	})
+}
+
+// RemoveCreature drops a creature from the index.
+func (d *DropIndex) RemoveCreature(title string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.remove(normalizeTitle(title))
+}
+
+// WhoDrops returns the creatures that drop an item, bosses and higher ranks first.
+// Item names match with or without their namespace prefix.
+func (d *DropIndex) WhoDrops(item string) []DropSource {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	key := dropKey(item)
+	var sources []DropSource
+	for title := range d.items[key] {
+		c := d.creatures[title]
+		for _, drop := range c.Drops {
+			if dropKey(drop.Item) != key {
+				continue
+			}
+			sources = append(sources, c.source(drop))
+		}
+	}
+
+	sort.Slice(sources, func(i, j int) bool {
+		a, b := sources[i], sources[j]
+		if a.Boss != b.Boss {
+			return a.Boss
+		}
+		if a.Rank != b.Rank {
+			return a.Rank > b.Rank
+		}
+		return a.Creature < b.Creature
+	})
+
+	return sources
+}
+
+// DropsOf returns the drops of an indexed creature.
+func (d *DropIndex) DropsOf(creature string) []CreatureDrop {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	c, ok := d.creatures[normalizeTitle(creature)]
+	if !ok {
+		return nil
+	}
+	return append([]CreatureDrop(nil), c.Drops...)
+}
+
+// CrawlCategory indexes every creature page in a category, such as "Creatures".
+// Creatures that are no longer in the category are removed.
+func (d *DropIndex) CrawlCategory(s *WikiService, category string) error {
+	titles, err := s.CategoryMembers(category)
+	if err != nil {
+		return err
+	}
+	if err := d.Update(s, titles); err != nil {
+		return err
+	}
+
+	wanted := make(map[string]bool, len(titles))
+	for _, title := range titles {
+		wanted[normalizeTitle(title)] = true
+	}
+	d.prune(wanted)
+
+	return nil
+}
+
+// Update brings the given creature pages up to date in the index.
+// Pages whose current revision is already indexed are not fetched again.
+func (d *DropIndex) Update(s *WikiService, titles []string) error {
+	names, err := s.infoboxNames("creature")
+	if err != nil {
+		return err
+	}
+	revisions, err := s.LatestRevisions(titles)
+	if err != nil {
+		return err
+	}
+
+	for _, title := range titles {
+		key := normalizeTitle(title)
+		revID, ok := revisions[key]
+		if !ok {
+			d.RemoveCreature(key)
+			continue
+		}
+		if d.revision(key) == revID {
+			continue
+		}
+
+		// Cached and local copies may predate the revision reported by the wiki.
+		page, err := s.fetchCurrent(key)
+		if errors.Is(err, ErrPageNotFound) {
+			d.RemoveCreature(key)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		creature, err := decodeCreature(page.Title, page.Content, names)
+		if errors.Is(err, ErrNoInfobox) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		d.AddCreature(key, page.RevID, creature)
+	}
+
+	return nil
+}
+
+// BuildFromStore indexes every creature page of a local page store, skipping unchanged revisions.
+// Creature pages are recognized by the infobox names of the service's profile.
+func (d *DropIndex) BuildFromStore(s *WikiService, store *DiskPageStore) error {
+	names, err := s.infoboxNames("creature")
+	if err != nil {
+		return err
+	}
+	titles, err := store.Titles()
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[string]bool, len(titles))
+	for _, title := range titles {
+		page, err := store.Get(title)
+		if err != nil {
+			return err
+		}
+		if _, ok := FindTemplate(page.Content, names...); !ok {
+			continue
+		}
+
+		key := normalizeTitle(page.Title)
+		wanted[key] = true
+		if page.RevID != 0 && d.revision(key) == page.RevID {
+			continue
+		}
+
+		creature, err := decodeCreature(page.Title, page.Content, names)
+		if err != nil {
+			return err
+		}
+		d.AddCreature(key, page.RevID, creature)
+	}
+
+	d.prune(wanted)
+
+	return nil
+}
+
+// each calls fn for every indexed creature while holding the read lock.
+func (d *DropIndex) each(fn func(c *indexedCreature)) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for _, c := range d.creatures {
+		fn(c)
+	}
+}
+
+// revision returns the indexed revision of a creature, or zero.
+func (d *DropIndex) revision(title string) int64 {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if c, ok := d.creatures[title]; ok {
+		return c.RevID
+	}
+	return 0
+}
+
+// prune removes creatures that are not in wanted.
+func (d *DropIndex) prune(wanted map[string]bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for title := range d.creatures {
+		if !wanted[title] {
+			d.remove(title)
+		}
+	}
+}
+
+// uniqueDrops keeps one drop per item, so that WhoDrops lists each creature once per item.
+// A drop with a category or chance is preferred over a bare mention of the same item.
+func uniqueDrops(drops []CreatureDrop) []CreatureDrop {
+	index := make(map[string]int, len(drops))
+	var kept []CreatureDrop
+	for _, drop := range drops {
+		key := dropKey(drop.Item)
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(kept)
+			kept = append(kept, drop)
+			continue
+		}
+		if kept[i].Category == "" {
+			kept[i].Category = drop.Category
+		}
+		if kept[i].Chance == "" {
+			kept[i].Chance = drop.Chance
+		}
+	}
+	return kept
+}
+
+// insert adds a creature, replacing an older entry with the same title.
+func (d *DropIndex) insert(c *indexedCreature) {
+	c.Drops = uniqueDrops(c.Drops)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.remove(c.Title)
+	d.creatures[c.Title] = c
+	for _, drop := range c.Drops {
+		key := dropKey(drop.Item)
+		if d.items[key] == nil {
+			d.items[key] = make(map[string]bool)
+		}
+		d.items[key][c.Title] = true
+	}
+}
+
+// remove drops a creature; the caller must hold the write lock.
+func (d *DropIndex) remove(title string) {
+	c, ok := d.creatures[title]
+	if !ok {
+		return
+	}
+
+	for _, drop := range c.Drops {
+		key := dropKey(drop.Item)
+		delete(d.items[key], title)
+		if len(d.items[key]) == 0 {
+			delete(d.items, key)
+		}
+	}
+	delete(d.creatures, title)
+}
+
+// source describes one of the creature's drops as a DropSource.
+func (c *indexedCreature) source(drop CreatureDrop) DropSource {
+	return DropSource{
+		Item:     drop.Item,
+		Creature: c.Title,
+		Location: c.Location,
+		World:    c.World,
+		Rank:     c.Rank,
+		Boss:     c.Boss,
+		Category: drop.Category,
+		Chance:   drop.Chance,
+	}
+}
+
+// dropKey normalizes an item name so that "Item:Fire Hat" and "fire hat" match.
+func dropKey(item string) string {
+	return strings.ToLower(normalizeTitle(stripNamespace(item)))
+}
@@ -0,0 +1,23 @@
+package wizlib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWhoDropsListsCreatureOnce(t *testing.T) {
+	d := NewDropIndex()
+	d.AddCreature("Boss", 1, &Creature{Drops: []CreatureDrop{
+		{Item: "Hat A"},
+		{Item: "Hat A", Category: "Hat", Chance: "Rare"},
+	}})
+
+	sources := d.WhoDrops("Hat A")
+	if len(sources) != 1 {
+		t.Fatalf("WhoDrops = %v, want one source", sources)
+	}
+	want := []CreatureDrop{{Item: "Hat A", Category: "Hat", Chance: "Rare"}}
+	if got := d.DropsOf("Boss"); !reflect.DeepEqual(got, want) {
+		t.Errorf("DropsOf = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,165 @@
+package wizlib
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/xml"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// dumpRevision represents a <revision> element of a MediaWiki XML export.
+type dumpRevision struct {
+	ID        int64     `xml:"id"`
+	Timestamp time.Time `xml:"timestamp"`
+	Text      string    `xml:"text"`
+}
+
+// DumpImporter streams a MediaWiki XML export or dump into a DiskPageStore.
+// Pages are decoded one revision at a time, so memory use does not grow with the size of the file.
+type DumpImporter struct {
+	Store      *DiskPageStore
+	Namespaces []int
+	Progress   func(imported int)
+}
+
+// NewDumpImporter creates a new instance of DumpImporter.
+func NewDumpImporter(store *DiskPageStore) *DumpImporter {
+	return &DumpImporter{Store: store}
+}
+
+// ImportFile imports the dump at path, decompressing .gz and .bz2 files on the fly.
+func (im *DumpImporter) ImportFile(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	switch {
+	case strings.HasSuffix(path, ".gz"):
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return 0, err
+		}
+		defer gz.Close()
+		r = gz
+	case strings.HasSuffix(path, ".bz2"):
+		r = bzip2.NewReader(file)
+	}
+
+	return im.Import(r)
+}
+
+// Import reads an XML dump from r and stores every page that is not a redirect.
+// It returns the number of pages imported.
+func (im *DumpImporter) Import(r io.Reader) (int, error) {
+	decoder := xml.NewDecoder(r)
+	imported := 0
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return imported, nil
+		}
+		if err != nil {
+			return imported, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "page" {
+			continue
+		}
+
+		page, keep, err := im.decodePage(decoder)
+		if err != nil {
+			return imported, err
+		}
+		if !keep || !im.wantNamespace(page.Namespace) {
+			continue
+		}
+
+		page.Infobox = extractInfoboxData(extractInfobox(page.Content))
+		page.Images = extractImages(page.Content)
+		if err := im.Store.Put(page); err != nil {
+			return imported, err
+		}
+
+		imported++
+		if im.Progress != nil {
+			im.Progress(imported)
+		}
+	}
+}
+
+// decodePage consumes the children of a <page> element, keeping only its latest revision.
+// Redirect pages are reported as not worth keeping.
+func (im *DumpImporter) decodePage(decoder *xml.Decoder) (StoredPage, bool, error) {
+	var page StoredPage
+	redirect := false
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return page, false, err
+		}
+
+		switch t := token.(type) {
+		case xml.EndElement:
+			if t.Name.Local == "page" {
+				return page, !redirect, nil
+			}
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "title":
+				if err := decoder.DecodeElement(&page.Title, &t); err != nil {
+					return page, false, err
+				}
+			case "ns":
+				if err := decoder.DecodeElement(&page.Namespace, &t); err != nil {
+					return page, false, err
+				}
+			case "id":
+				if err := decoder.DecodeElement(&page.PageID, &t); err != nil {
+					return page, false, err
+				}
+			case "redirect":
+				redirect = true
+				if err := decoder.Skip(); err != nil {
+					return page, false, err
+				}
+			case "revision":
+				var revision dumpRevision
+				if err := decoder.DecodeElement(&revision, &t); err != nil {
+					return page, false, err
+				}
+				// Full-history dumps list revisions oldest first; keep the newest.
+				if revision.ID >= page.RevID {
+					page.RevID = revision.ID
+					page.Timestamp = revision.Timestamp
+					page.Content = revision.Text
+				}
+			default:
+				if err := decoder.Skip(); err != nil {
+					return page, false, err
+				}
+			}
+		}
+	}
+}
+
+// wantNamespace reports whether pages in the given namespace should be imported.
+func (im *DumpImporter) wantNamespace(ns int) bool {
+	if len(im.Namespaces) == 0 {
+		return true
+	}
+	for _, n := range im.Namespaces {
+		if n == ns {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,221 @@
+package wizlib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrEditConflict is returned when a page changed after the revision an edit was based on.
+var ErrEditConflict = errors.New("edit conflict")
+
+// ErrUnsafeValue is returned when a template value would change the structure of the template it is written to.
+var ErrUnsafeValue = errors.New("value would break the template")
+
+// EditResult describes a saved edit.
+type EditResult struct {
+	Title    string `json:"title"`
+	PageID   int64  `json:"pageid"`
+	OldRevID int64  `json:"oldrevid"`
+	NewRevID int64  `json:"newrevid"`
+	NoChange bool   `json:"nochange"`
+}
+
+// Edit replaces the text of a page as a bot edit. The edit is based on the revision last returned by
+// GetWikiText for the page, or the current revision if the page is no longer cached, and fails with
+// ErrEditConflict if someone else saved the page since. Pages that do not exist yet are created
+// with EditRevision and a zero baseRevID.
+// The service must be logged in with a bot password that grants edit rights.
+func (s *WikiService) Edit(title, text, summary string) (*EditResult, error) {
+	var baseRevID int64
+	if cached, ok := s.Cache.Get(normalizeTitle(title)); ok {
+		if response, ok := cached.(WikiResponse); ok {
+			baseRevID = response.Parse.RevID
+		}
+	}
+	if baseRevID == 0 {
+		revisions, err := s.LatestRevisions([]string{title})
+		if err != nil {
+			return nil, err
+		}
+		if baseRevID = revisions[normalizeTitle(title)]; baseRevID == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrPageNotFound, title)
+		}
+	}
+	return s.EditRevision(title, text, summary, baseRevID)
+}
+
+// EditRevision replaces the text of a page, failing with ErrEditConflict if the page changed after
+// baseRevID. A zero baseRevID skips conflict detection.
+func (s *WikiService) EditRevision(title, text, summary string, baseRevID int64) (*EditResult, error) {
+	result, err := s.edit(title, text, summary, baseRevID)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Code == "badtoken" {
+		// The session was renewed since the token was issued.
+		result, err = s.edit(title, text, summary, baseRevID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.Invalidate(title)
+	return result, nil
+}
+
+// edit performs a single action=edit request with a fresh CSRF token.
+func (s *WikiService) edit(title, text, summary string, baseRevID int64) (*EditResult, error) {
+	token, err := s.token("csrf")
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("action", "edit")
+	params.Set("title", title)
+	params.Set("text", text)
+	params.Set("summary", summary)
+	params.Set("bot", "1")
+	params.Set("assert", "bot")
+	if baseRevID != 0 {
+		params.Set("baserevid", strconv.FormatInt(baseRevID, 10))
+	}
+	params.Set("token", token)
+
+	body, err := s.post(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Edit struct {
+			EditResult
+			Result string `json:"result"`
+		} `json:"edit"`
+		Error *APIError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		if response.Error.Code == "editconflict" {
+			return nil, fmt.Errorf("%w: %s", ErrEditConflict, title)
+		}
+		return nil, response.Error
+	}
+	if response.Edit.Result != "Success" {
+		return nil, fmt.Errorf("mediawiki: edit %s: %s", title, response.Edit.Result)
+	}
+
+	return &response.Edit.EditResult, nil
+}
+
+// UpdateInfoboxField sets one parameter of the first matching template on a page and saves it,
+// leaving the rest of the page text untouched. A missing parameter is added after the last
+// named parameter in the same style. The value is escaped as described for SetTemplateField.
+// Nothing is saved when the value is unchanged.
+func (s *WikiService) UpdateInfoboxField(title, template, key, value string) (*EditResult, error) {
+	// Read the current raw page: GetWikiText may expand templates, and a local copy may be stale.
+	page, err := s.fetchCurrent(title)
+	if err != nil {
+		return nil, err
+	}
+
+	text, changed, err := SetTemplateField(page.Content, template, key, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", title, err)
+	}
+	if !changed {
+		return &EditResult{Title: page.Title, PageID: page.PageID, OldRevID: page.RevID, NewRevID: page.RevID, NoChange: true}, nil
+	}
+
+	summary := fmt.Sprintf("Set %s in %s", strings.TrimSpace(key), normalizeTitle(template))
+	return s.EditRevision(page.Title, text, summary, page.RevID)
+}
+
+// SetTemplateField returns wikiText with a parameter of the first matching template set to value,
+// and whether anything changed. Only the parameter's value, or the inserted parameter, differs.
+// The value may contain complete templates and links; a "|" outside them is written as {{!}}, and
+// a value with unbalanced braces or brackets is rejected with ErrUnsafeValue.
+func SetTemplateField(wikiText, template, key, value string) (string, bool, error) {
+	t, ok := FindTemplate(wikiText, template)
+	if !ok {
+		return "", false, fmt.Errorf("template %s: %w", template, ErrNoInfobox)
+	}
+	value, err := escapeTemplateValue(strings.TrimSpace(value))
+	if err != nil {
+		return "", false, err
+	}
+
+	// MediaWiki uses the last occurrence of a repeated parameter.
+	for i := len(t.Params) - 1; i >= 0; i-- {
+		p := t.Params[i]
+		if normalizeKey(p.Key) != normalizeKey(key) || (!p.Named && p.Key != key) {
+			continue
+		}
+		if p.Value == value {
+			return wikiText, false, nil
+		}
+		return wikiText[:p.ValueStart] + value + wikiText[p.ValueEnd:], true, nil
+	}
+
+	// Copy the spacing of the last named parameter, such as "| level = ", on a new line if the template uses them.
+	for i := len(t.Params) - 1; i >= 0; i-- {
+		p := t.Params[i]
+		if !p.Named {
+			continue
+		}
+		pipe := strings.LastIndex(wikiText[t.Start:p.ValueStart], "|") + t.Start
+		prefix := strings.Replace(wikiText[pipe:p.ValueStart], p.Key, strings.TrimSpace(key), 1)
+		lead := ""
+		if strings.Contains(wikiText[t.Start:t.End], "\n|") {
+			lead = "\n"
+		}
+		return wikiText[:p.ValueEnd] + lead + prefix + value + wikiText[p.ValueEnd:], true, nil
+	}
+
+	end := t.End - 2
+	return wikiText[:end] + "|" + strings.TrimSpace(key) + "=" + value + wikiText[end:], true, nil
+}
+
+// escapeTemplateValue escapes the pipes of a value that would otherwise start a new template
+// parameter, and rejects values whose braces or brackets would close the template early or
+// swallow the rest of it.
+func escapeTemplateValue(value string) (string, error) {
+	var sb strings.Builder
+	braces, brackets := 0, 0
+	for i := 0; i < len(value); i++ {
+		if i+1 < len(value) {
+			switch value[i : i+2] {
+			case "{{":
+				braces++
+			case "}}":
+				braces--
+			case "[[":
+				brackets++
+			case "]]":
+				brackets--
+			}
+			if braces < 0 || brackets < 0 {
+				return "", fmt.Errorf("%w: %q", ErrUnsafeValue, value)
+			}
+			switch value[i : i+2] {
+			case "{{", "}}", "[[", "]]":
+				sb.WriteString(value[i : i+2])
+				i++
+				continue
+			}
+		}
+		if value[i] == '|' && braces == 0 && brackets == 0 {
+			sb.WriteString("{{!}}")
+			continue
+		}
+		sb.WriteByte(value[i])
+	}
+	if braces != 0 || brackets != 0 {
+		return "", fmt.Errorf("%w: %q", ErrUnsafeValue, value)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,79 @@
+package wizlib
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSetTemplateFieldEscapes(t *testing.T) {
+	text := "{{ItemInfobox\n|level = 10\n}}"
+
+	got, changed, err := SetTemplateField(text, "ItemInfobox", "level", "a|b")
+	if err != nil || !changed {
+		t.Fatalf("SetTemplateField: %v, changed %v", err, changed)
+	}
+	if want := "{{ItemInfobox\n|level = a{{!}}b\n}}"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	got, _, err = SetTemplateField(text, "ItemInfobox", "level", "{{Icon|Fire}} [[Fire|fire]]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{{ItemInfobox\n|level = {{Icon|Fire}} [[Fire|fire]]\n}}"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	for _, value := range []string{"10}}", "{{Icon", "x]] [["} {
+		if _, _, err := SetTemplateField(text, "ItemInfobox", "level", value); !errors.Is(err, ErrUnsafeValue) {
+			t.Errorf("SetTemplateField(%q): got %v, want ErrUnsafeValue", value, err)
+		}
+	}
+}
+
+func TestEditUncachedUsesCurrentRevision(t *testing.T) {
+	w, s := newFakeWiki(t)
+	if err := s.Login("Bot@Test", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	w.pages["Fire Hat"].RevID = 120
+
+	result, err := s.Edit("Fire Hat", "new text", "test")
+	if err != nil {
+		t.Fatalf("Edit: %v", err)
+	}
+	if result.OldRevID != 120 {
+		t.Errorf("OldRevID = %d, want 120", result.OldRevID)
+	}
+
+	if _, err := s.Edit("No Such Page", "text", "test"); !errors.Is(err, ErrPageNotFound) {
+		t.Errorf("Edit of a missing page: got %v, want ErrPageNotFound", err)
+	}
+}
+
+func TestUpdateInfoboxFieldReadsLivePage(t *testing.T) {
+	w, s := newFakeWiki(t)
+	if err := s.Login("Bot@Test", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewDiskPageStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(StoredPage{Title: "Fire Hat", PageID: 7, RevID: 90, Content: "{{ItemInfobox\n|level = 5\n}}"}); err != nil {
+		t.Fatal(err)
+	}
+	s.Source = NewLayeredSource(store, s.Source)
+
+	result, err := s.UpdateInfoboxField("Fire Hat", "ItemInfobox", "level", "12")
+	if err != nil {
+		t.Fatalf("UpdateInfoboxField: %v", err)
+	}
+	if result.OldRevID != 100 {
+		t.Errorf("OldRevID = %d, want the live revision 100", result.OldRevID)
+	}
+	if want := "{{ItemInfobox\n|level = 12\n}}"; w.pages["Fire Hat"].Text != want {
+		t.Errorf("page text = %q, want %q", w.pages["Fire Hat"].Text, want)
+	}
+}
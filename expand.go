@@ -0,0 +1,204 @@
+package wizlib
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// expandSeparator separates the values expanded in a single request; it contains no wikitext markup.
+const expandSeparator = "\n@@WIZLIB-EXPAND-SEPARATOR@@\n"
+
+// expandBatchBytes limits the text sent in one expandtemplates request.
+const expandBatchBytes = 2000
+
+// ExpandTemplatesIn makes GetWikiText expand the templates inside the given parameters of a template,
+// such as computed stat totals in an ItemInfobox, before pages are returned and decoded.
+// Without keys, every parameter of the template is expanded. GetRawWikiText still returns pages unexpanded.
+func (s *WikiService) ExpandTemplatesIn(template string, keys ...string) {
+	s.expandMu.Lock()
+	defer s.expandMu.Unlock()
+
+	if s.expand == nil {
+		s.expand = make(map[string][]string)
+	}
+	normalized := make([]string, 0, len(keys))
+	for _, key := range keys {
+		normalized = append(normalized, normalizeKey(key))
+	}
+	s.expand[strings.ToLower(normalizeTitle(template))] = normalized
+	// Pages expanded under the previous settings are no longer used.
+	s.expandGen++
+}
+
+// ExpandTemplates expands the templates in each text as if it appeared on the given page, through
+// action=expandtemplates. Texts are sent in batches and their expansions are cached.
+func (s *WikiService) ExpandTemplates(title string, texts []string) ([]string, error) {
+	expanded := make([]string, len(texts))
+	var missing []int
+	for i, text := range texts {
+		if cached, ok := s.Cache.Get(expandCacheKey(title, text)); ok {
+			if value, ok := cached.(string); ok {
+				expanded[i] = value
+				continue
+			}
+		}
+		missing = append(missing, i)
+	}
+
+	for len(missing) > 0 {
+		n, size := 0, 0
+		for n < len(missing) && (n == 0 || size+len(texts[missing[n]]) <= expandBatchBytes) {
+			size += len(texts[missing[n]]) + len(expandSeparator)
+			n++
+		}
+
+		batch := make([]string, n)
+		for j, i := range missing[:n] {
+			batch[j] = texts[i]
+		}
+		results, err := s.expandBatch(title, batch)
+		if err != nil {
+			return nil, err
+		}
+
+		for j, i := range missing[:n] {
+			expanded[i] = results[j]
+			s.Cache.SetEntry(expandCacheKey(title, texts[i]), title, results[j], int64(len(texts[i])+len(results[j])), 0)
+		}
+		missing = missing[n:]
+	}
+
+	return expanded, nil
+}
+
+// expandBatch expands several texts in one request, falling back to one request per text
+// if the expansion swallowed a separator.
+func (s *WikiService) expandBatch(title string, texts []string) ([]string, error) {
+	params := url.Values{}
+	params.Set("action", "expandtemplates")
+	params.Set("prop", "wikitext")
+	params.Set("title", title)
+	params.Set("text", strings.Join(texts, expandSeparator))
+
+	// The text is sent in the request body, since it can grow past URL limits once encoded.
+	body, err := s.post(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		ExpandTemplates struct {
+			WikiText string `json:"wikitext"`
+		} `json:"expandtemplates"`
+		Error *APIError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		return nil, response.Error
+	}
+
+	results := strings.Split(response.ExpandTemplates.WikiText, expandSeparator)
+	if len(results) == len(texts) {
+		for i := range results {
+			results[i] = strings.TrimSpace(results[i])
+		}
+		return results, nil
+	}
+	if len(texts) == 1 {
+		return []string{strings.TrimSpace(response.ExpandTemplates.WikiText)}, nil
+	}
+
+	results = make([]string, 0, len(texts))
+	for _, text := range texts {
+		result, err := s.expandBatch(title, []string{text})
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result...)
+	}
+	return results, nil
+}
+
+// expandResponse returns a page with the templates selected with ExpandTemplatesIn expanded.
+// Expanded pages are cached apart from the raw ones; when the expansion fails, the raw page is returned.
+func (s *WikiService) expandResponse(response WikiResponse) WikiResponse {
+	// The settings are copied so that ExpandTemplatesIn does not wait for the requests below.
+	s.expandMu.RLock()
+	selected := make(map[string][]string, len(s.expand))
+	for template, keys := range s.expand {
+		selected[template] = keys
+	}
+	gen := s.expandGen
+	s.expandMu.RUnlock()
+
+	if len(selected) == 0 {
+		return response
+	}
+
+	key := fmt.Sprintf("#expanded%d:%s", gen, normalizeTitle(response.Parse.Title))
+	if cached, ok := s.Cache.Get(key); ok {
+		if expanded, ok := cached.(WikiResponse); ok && expanded.Parse.RevID == response.Parse.RevID {
+			return expanded
+		}
+	}
+
+	content, err := s.expandContent(response.Parse.Title, response.Parse.Content, selected)
+	if err != nil {
+		return response
+	}
+	response.Parse.Content = content
+	s.Cache.SetEntry(key, response.Parse.Title, response, responseSize(response), 0)
+
+	return response
+}
+
+// expandContent expands the templates inside the selected parameters, keyed by lowercase template name
+// as set with ExpandTemplatesIn.
+func (s *WikiService) expandContent(title, content string, selected map[string][]string) (string, error) {
+	var params []TemplateParam
+	for _, t := range ParseTemplates(content) {
+		keys, ok := selected[strings.ToLower(normalizeTitle(t.Name))]
+		if !ok {
+			continue
+		}
+		for _, p := range t.Params {
+			if strings.Contains(p.Value, "{{") && (len(keys) == 0 || containsString(keys, normalizeKey(p.Key))) {
+				params = append(params, p)
+			}
+		}
+	}
+	if len(params) == 0 {
+		return content, nil
+	}
+
+	texts := make([]string, len(params))
+	for i, p := range params {
+		texts[i] = p.Value
+	}
+	expanded, err := s.ExpandTemplates(title, texts)
+	if err != nil {
+		return "", err
+	}
+
+	// Replace values from the end so that earlier offsets stay valid.
+	order := make([]int, len(params))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return params[order[a]].ValueStart > params[order[b]].ValueStart })
+	for _, i := range order {
+		content = content[:params[i].ValueStart] + expanded[i] + content[params[i].ValueEnd:]
+	}
+
+	return content, nil
+}
+
+// expandCacheKey returns the cache key of an expansion; "#" cannot appear in page titles, so keys never collide.
+func expandCacheKey(title, text string) string {
+	return "#expand:" + normalizeTitle(title) + "\x00" + text
+}
@@ -0,0 +1,666 @@
+package wizlib
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ExportEntity describes a type of entity to export and where to find its pages.
+type ExportEntity struct {
+	// Name is used for file and table names, such as "items".
+	Name     string
+	Category string
+	Decode   func(s *WikiService, title string) (interface{}, error)
+}
+
+// DefaultExportEntities lists the typed Wizard101 entities the library can decode.
+// Exports from other wikis fail with ErrUnsupportedKind; see PirateExportEntities.
+var DefaultExportEntities = []ExportEntity{
+	{Name: "items", Category: "Items", Decode: func(s *WikiService, title string) (interface{}, error) { return s.GetItem(title) }},
+	{Name: "spells", Category: "Spells", Decode: func(s *WikiService, title string) (interface{}, error) { return s.GetSpell(title) }},
+	{Name: "creatures", Category: "Creatures", Decode: func(s *WikiService, title string) (interface{}, error) { return s.GetCreature(title) }},
+	{Name: "recipes", Category: "Recipes", Decode: func(s *WikiService, title string) (interface{}, error) { return s.GetRecipe(title) }},
+	{Name: "quests", Category: "Quests", Decode: func(s *WikiService, title string) (interface{}, error) { return s.GetQuest(title) }},
+	{Name: "pets", Category: "Pets", Decode: func(s *WikiService, title string) (interface{}, error) { return s.GetPet(title) }},
+	{Name: "locations", Category: "Locations", Decode: func(s *WikiService, title string) (interface{}, error) { return s.GetLocation(title) }},
+}
+
+// EntityWriter receives exported records. Records are decoded entities as JSON objects with a "title" key.
+type EntityWriter interface {
+	Write(entity string, record map[string]interface{}) error
+	// Flush writes out every record written so far, so that the export can be resumed after it.
+	Flush() error
+	Close() error
+}
+
+// Exporter walks categories, decodes their pages and hands the records to writers.
+type Exporter struct {
+	Service   *WikiService
+	Writers   []EntityWriter
+	StatePath string
+	Progress  func(entity string, done, total int)
+
+	done  map[string]map[string]bool
+	state *os.File
+}
+
+// exportEntry is a line of the exporter state, recording an exported page.
+type exportEntry struct {
+	Entity string `json:"entity"`
+	Title  string `json:"title"`
+}
+
+// NewExporter creates a new instance of Exporter, resuming from the state at statePath if it exists.
+// Writers must append to earlier output when resuming, as the provided writers do.
+func NewExporter(service *WikiService, statePath string, writers ...EntityWriter) (*Exporter, error) {
+	e := &Exporter{
+		Service:   service,
+		Writers:   writers,
+		StatePath: statePath,
+		done:      make(map[string]map[string]bool),
+	}
+
+	if statePath == "" {
+		return e, nil
+	}
+
+	data, err := os.ReadFile(statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return e, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The state is a log of exported pages, one JSON object per line. An interrupted run may leave
+	// a partial last line, which is cut off so that the next entry starts on a line of its own.
+	if end := bytes.LastIndexByte(data, '\n') + 1; end < len(data) {
+		if err := os.Truncate(statePath, int64(end)); err != nil {
+			return nil, err
+		}
+		data = data[:end]
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		var entry exportEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			continue
+		}
+		if e.done[entry.Entity] == nil {
+			e.done[entry.Entity] = make(map[string]bool)
+		}
+		e.done[entry.Entity][entry.Title] = true
+	}
+
+	return e, nil
+}
+
+// Export exports every page of each entity's category. Pages exported by an earlier, interrupted run are skipped,
+// as are pages that are missing or lack the expected infobox.
+func (e *Exporter) Export(entities ...ExportEntity) error {
+	for _, entity := range entities {
+		titles, err := e.Service.CategoryMembers(entity.Category)
+		if err != nil {
+			return err
+		}
+		if e.done[entity.Name] == nil {
+			e.done[entity.Name] = make(map[string]bool)
+		}
+
+		for i, title := range titles {
+			title = normalizeTitle(title)
+			if e.done[entity.Name][title] {
+				continue
+			}
+
+			if err := e.export(entity, title); err != nil {
+				return fmt.Errorf("%s: %w", title, err)
+			}
+			if err := e.commit(entity.Name, title); err != nil {
+				return err
+			}
+			e.done[entity.Name][title] = true
+
+			if e.Progress != nil {
+				e.Progress(entity.Name, i+1, len(titles))
+			}
+		}
+	}
+
+	return nil
+}
+
+// Close closes every writer and the state file.
+func (e *Exporter) Close() error {
+	var first error
+	if e.state != nil {
+		first = e.state.Close()
+	}
+	for _, w := range e.Writers {
+		if err := w.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// export decodes one page and writes it to every writer.
+func (e *Exporter) export(entity ExportEntity, title string) error {
+	decoded, err := entity.Decode(e.Service, title)
+	if errors.Is(err, ErrPageNotFound) || errors.Is(err, ErrNoInfobox) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	record, err := toRecord(decoded)
+	if err != nil {
+		return err
+	}
+	record["title"] = title
+
+	for _, w := range e.Writers {
+		if err := w.Write(entity.Name, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commit flushes the writers to disk and then records the page as exported. A crash in between
+// leaves the record in the output but not in the state; the writers skip it when it is exported again.
+func (e *Exporter) commit(entity, title string) error {
+	for _, w := range e.Writers {
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	if e.StatePath == "" {
+		return nil
+	}
+
+	if e.state == nil {
+		f, err := os.OpenFile(e.StatePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		e.state = f
+	}
+
+	data, err := json.Marshal(exportEntry{Entity: entity, Title: title})
+	if err != nil {
+		return err
+	}
+	_, err = e.state.Write(append(data, '\n'))
+	return err
+}
+
+// toRecord converts a decoded entity to a JSON object using its JSON tags.
+func toRecord(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	if record == nil {
+		record = make(map[string]interface{})
+	}
+	return record, nil
+}
+
+// JSONLWriter writes one JSON Lines file per entity, such as "items.jsonl", appending to existing files.
+// A partial last line left by an interrupted run is removed, and records whose title is already in
+// the file are skipped, so that a resumed export neither corrupts nor duplicates records.
+type JSONLWriter struct {
+	Dir string
+
+	files  map[string]*os.File
+	bufs   map[string]*bufio.Writer
+	titles map[string]map[string]bool
+}
+
+// NewJSONLWriter creates a new instance of JSONLWriter writing to dir.
+func NewJSONLWriter(dir string) (*JSONLWriter, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &JSONLWriter{
+		Dir:    dir,
+		files:  make(map[string]*os.File),
+		bufs:   make(map[string]*bufio.Writer),
+		titles: make(map[string]map[string]bool),
+	}, nil
+}
+
+// Write appends a record to the entity's file.
+func (w *JSONLWriter) Write(entity string, record map[string]interface{}) error {
+	buf, ok := w.bufs[entity]
+	if !ok {
+		var err error
+		if buf, err = w.open(entity); err != nil {
+			return err
+		}
+	}
+
+	title := formatValue(record["title"])
+	if title != "" && w.titles[entity][title] {
+		return nil
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	buf.Write(data)
+	if err := buf.WriteByte('\n'); err != nil {
+		return err
+	}
+	w.titles[entity][title] = true
+	return nil
+}
+
+// open opens the entity's file for appending, after cutting off a partial last line and reading
+// the titles already written.
+func (w *JSONLWriter) open(entity string) (*bufio.Writer, error) {
+	path := filepath.Join(w.Dir, entity+".jsonl")
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if end := bytes.LastIndexByte(data, '\n') + 1; end < len(data) {
+		if err := os.Truncate(path, int64(end)); err != nil {
+			return nil, err
+		}
+		data = data[:end]
+	}
+
+	titles := make(map[string]bool)
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		var record struct {
+			Title string `json:"title"`
+		}
+		if json.Unmarshal(line, &record) == nil && record.Title != "" {
+			titles[record.Title] = true
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewWriter(f)
+	w.files[entity] = f
+	w.bufs[entity] = buf
+	w.titles[entity] = titles
+	return buf, nil
+}
+
+// Flush writes buffered records to disk and syncs the files.
+func (w *JSONLWriter) Flush() error {
+	return flushFiles(w.files, func(entity string) error { return w.bufs[entity].Flush() })
+}
+
+// Close flushes and closes every file.
+func (w *JSONLWriter) Close() error {
+	err := w.Flush()
+	for _, f := range w.files {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// FlattenOptions controls how nested records become flat columns.
+type FlattenOptions struct {
+	// Separator joins the keys of nested objects, as in "stats.0.value".
+	Separator string
+	// ListSeparator joins lists of plain values into a single column.
+	ListSeparator string
+	// MaxDepth is the nesting depth below which values are kept as JSON text; zero keeps every top-level value whole.
+	MaxDepth int
+	// Omit lists top-level keys to leave out, such as "infobox".
+	Omit []string
+}
+
+// DefaultFlattenOptions flattens nested objects two levels deep and joins lists with "; ".
+var DefaultFlattenOptions = FlattenOptions{Separator: ".", ListSeparator: "; ", MaxDepth: 2}
+
+// Flatten turns a record into columns holding strings, numbers, booleans or nil.
+func (o FlattenOptions) Flatten(record map[string]interface{}) map[string]interface{} {
+	columns := make(map[string]interface{})
+	for key, value := range record {
+		if containsString(o.Omit, key) {
+			continue
+		}
+		o.flatten(columns, key, value, 0)
+	}
+	return columns
+}
+
+// flatten stores value under key, expanding objects and lists until MaxDepth.
+func (o FlattenOptions) flatten(columns map[string]interface{}, key string, value interface{}, depth int) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if depth < o.MaxDepth {
+			for k, item := range v {
+				o.flatten(columns, key+o.Separator+k, item, depth+1)
+			}
+			return
+		}
+	case []interface{}:
+		if joined, ok := o.join(v); ok {
+			columns[key] = joined
+			return
+		}
+		if depth < o.MaxDepth {
+			for i, item := range v {
+				o.flatten(columns, key+o.Separator+strconv.Itoa(i), item, depth+1)
+			}
+			return
+		}
+	default:
+		columns[key] = v
+		return
+	}
+
+	data, _ := json.Marshal(value)
+	columns[key] = string(data)
+}
+
+// join joins a list of plain values, reporting false if the list holds objects or lists.
+func (o FlattenOptions) join(list []interface{}) (string, bool) {
+	parts := make([]string, 0, len(list))
+	for _, item := range list {
+		switch item.(type) {
+		case map[string]interface{}, []interface{}:
+			return "", false
+		}
+		parts = append(parts, formatValue(item))
+	}
+	return strings.Join(parts, o.ListSeparator), true
+}
+
+// formatValue formats a flattened value as text.
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(v)
+}
+
+// CSVWriter writes one CSV file per entity, such as "items.csv", appending to existing files.
+// When Columns is set for an entity, the file has exactly those columns and other keys are left out.
+// Otherwise the header starts with the keys of the first record, and the file is rewritten with
+// additional columns when a later record has keys the header lacks.
+// As with JSONLWriter, an incomplete last row is removed and records whose title is already in the
+// file are skipped when resuming.
+type CSVWriter struct {
+	Dir     string
+	Flatten FlattenOptions
+	Columns map[string][]string
+
+	files   map[string]*os.File
+	writers map[string]*csv.Writer
+	headers map[string][]string
+	titles  map[string]map[string]bool
+}
+
+// NewCSVWriter creates a new instance of CSVWriter writing to dir.
+func NewCSVWriter(dir string) (*CSVWriter, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &CSVWriter{
+		Dir:     dir,
+		Flatten: DefaultFlattenOptions,
+		Columns: make(map[string][]string),
+		files:   make(map[string]*os.File),
+		writers: make(map[string]*csv.Writer),
+		headers: make(map[string][]string),
+		titles:  make(map[string]map[string]bool),
+	}, nil
+}
+
+// Write appends a flattened record to the entity's file.
+func (w *CSVWriter) Write(entity string, record map[string]interface{}) error {
+	columns := w.Flatten.Flatten(record)
+
+	if _, ok := w.writers[entity]; !ok {
+		if err := w.open(entity, columns); err != nil {
+			return err
+		}
+	}
+
+	title := formatValue(columns["title"])
+	if title != "" && w.titles[entity][title] {
+		return nil
+	}
+
+	if len(w.Columns[entity]) == 0 {
+		var extra []string
+		for _, column := range sortedKeys(columns) {
+			if !containsString(w.headers[entity], column) {
+				extra = append(extra, column)
+			}
+		}
+		if len(extra) > 0 {
+			if err := w.widen(entity, extra); err != nil {
+				return err
+			}
+		}
+	}
+
+	header := w.headers[entity]
+	row := make([]string, len(header))
+	for i, column := range header {
+		row[i] = formatValue(columns[column])
+	}
+	if err := w.writers[entity].Write(row); err != nil {
+		return err
+	}
+	w.titles[entity][title] = true
+	return nil
+}
+
+// open opens the entity's file, reading or writing its header.
+func (w *CSVWriter) open(entity string, first map[string]interface{}) error {
+	path := filepath.Join(w.Dir, entity+".csv")
+
+	rows, err := readCSV(path)
+	if err != nil {
+		return err
+	}
+
+	titles := make(map[string]bool)
+	var header []string
+	if len(rows) > 0 {
+		header = rows[0]
+		if col := indexOf(header, "title"); col >= 0 {
+			for _, row := range rows[1:] {
+				titles[row[col]] = true
+			}
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(f)
+
+	if header == nil {
+		header = w.Columns[entity]
+		if len(header) == 0 {
+			header = sortedKeys(first)
+			// Keep the title first, where spreadsheets show it next to the row numbers.
+			for i, column := range header {
+				if column == "title" {
+					header = append([]string{"title"}, append(header[:i:i], header[i+1:]...)...)
+					break
+				}
+			}
+		}
+		if err := cw.Write(header); err != nil {
+			f.Close()
+			return err
+		}
+	}
+
+	w.files[entity] = f
+	w.writers[entity] = cw
+	w.headers[entity] = header
+	w.titles[entity] = titles
+	return nil
+}
+
+// widen rewrites the entity's file with extra columns appended to the header, leaving them empty in
+// the rows written so far.
+func (w *CSVWriter) widen(entity string, extra []string) error {
+	cw := w.writers[entity]
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+	if err := w.files[entity].Close(); err != nil {
+		return err
+	}
+	delete(w.files, entity)
+	delete(w.writers, entity)
+
+	path := filepath.Join(w.Dir, entity+".csv")
+	rows, err := readCSV(path)
+	if err != nil {
+		return err
+	}
+
+	header := append(append([]string(nil), w.headers[entity]...), extra...)
+	var buf bytes.Buffer
+	out := csv.NewWriter(&buf)
+	out.Write(header)
+	if len(rows) > 0 {
+		for _, row := range rows[1:] {
+			out.Write(append(row, make([]string, len(extra))...))
+		}
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, buf.Bytes()); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	w.files[entity] = f
+	w.writers[entity] = csv.NewWriter(f)
+	w.headers[entity] = header
+	return nil
+}
+
+// readCSV returns the complete rows of an existing CSV file, header first, or nil if there is none.
+// An incomplete last row, left by an interrupted run, is cut off the file.
+func readCSV(path string) ([][]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	var rows [][]string
+	end := int64(0)
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		// A row only counts as complete once its line break was written.
+		offset := r.InputOffset()
+		if err != nil || offset == 0 || data[offset-1] != '\n' {
+			break
+		}
+		if len(rows) > 0 && len(row) != len(rows[0]) {
+			break
+		}
+		rows = append(rows, row)
+		end = offset
+	}
+
+	if end < int64(len(data)) {
+		if err := os.Truncate(path, end); err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+// indexOf returns the index of s in list, or -1.
+func indexOf(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// Flush writes buffered rows to disk and syncs the files.
+func (w *CSVWriter) Flush() error {
+	return flushFiles(w.files, func(entity string) error {
+		w.writers[entity].Flush()
+		return w.writers[entity].Error()
+	})
+}
+
+// Close flushes and closes every file.
+func (w *CSVWriter) Close() error {
+	err := w.Flush()
+	for _, f := range w.files {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// flushFiles flushes the buffer of every open file in a stable order and syncs the file to disk,
+// so that the records are durable before the exporter records them as done.
+func flushFiles(files map[string]*os.File, flush func(entity string) error) error {
+	for _, entity := range sortedKeys(files) {
+		if err := flush(entity); err != nil {
+			return err
+		}
+		if err := files[entity].Sync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package wizlib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCSVWriterAddsColumns(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewCSVWriter(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write("items", map[string]interface{}{"title": "Hat", "level": 10.0}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write("items", map[string]interface{}{"title": "Robe", "level": 20.0, "school": "Fire"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "items.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "title,level,school\nHat,10,\nRobe,20,Fire\n"
+	if string(data) != want {
+		t.Errorf("items.csv = %q, want %q", data, want)
+	}
+}
+
+func TestJSONLWriterResume(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "items.jsonl")
+	// An interrupted run wrote Hat completely and Robe partially.
+	if err := os.WriteFile(path, []byte(`{"title":"Hat"}`+"\n"+`{"title":"Ro`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewJSONLWriter(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"Hat", "Robe"} {
+		if err := w.Write("items", map[string]interface{}{"title": title}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	want := []string{`{"title":"Hat"}`, `{"title":"Robe"}`}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("items.jsonl lines = %q, want %q", lines, want)
+	}
+}
@@ -0,0 +1,229 @@
+package wizlib
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// RenderedInfobox is an infobox read from rendered HTML, where templates and computed values such as
+// stat totals have been filled in by MediaWiki.
+type RenderedInfobox struct {
+	Title  string            `json:"title,omitempty"`
+	Fields map[string]string `json:"fields"`
+	Table  WikiTable         `json:"table"`
+}
+
+// NavboxGroup is one labelled row of links in a navbox.
+type NavboxGroup struct {
+	Name  string   `json:"name,omitempty"`
+	Links []string `json:"links"`
+}
+
+// Navbox is a navigation box listing related pages, such as every spell of a school.
+type Navbox struct {
+	Title  string        `json:"title"`
+	Groups []NavboxGroup `json:"groups"`
+}
+
+// GalleryImage is one image of a <gallery>.
+type GalleryImage struct {
+	File    string `json:"file"`
+	Caption string `json:"caption,omitempty"`
+}
+
+// GetRenderedHTML retrieves the HTML MediaWiki renders for a page (action=parse&prop=text).
+// The HTML is cached with the page's wikitext and shares its rate limits, so Invalidate removes both.
+// A new document is returned on every call, so callers may modify it. Pages that were not rendered
+// before fail with ErrOffline when the service has no APIClient.
+func (s *WikiService) GetRenderedHTML(pageName string) (*goquery.Document, error) {
+	key := normalizeTitle(pageName)
+	if cached, ok := s.Cache.Get(key); ok {
+		if missing, ok := cached.(*MissingPageError); ok {
+			return nil, missing
+		}
+	}
+
+	html, err := s.renderedHTML(key)
+	if err != nil {
+		return nil, err
+	}
+	return goquery.NewDocumentFromReader(strings.NewReader(html))
+}
+
+// renderedHTML returns the cached HTML of a page, fetching it if needed.
+func (s *WikiService) renderedHTML(key string) (string, error) {
+	htmlKey := "#html:" + key
+	if cached, ok := s.Cache.Get(htmlKey); ok {
+		if html, ok := cached.(string); ok {
+			return html, nil
+		}
+	}
+	if s.Client == nil {
+		return "", ErrOffline
+	}
+
+	params := url.Values{}
+	params.Set("action", "parse")
+	params.Set("page", key)
+	params.Set("prop", "text")
+	params.Set("disableeditsection", "1")
+
+	body, err := s.query(params)
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Parse struct {
+			Title string `json:"title"`
+			Text  string `json:"text"`
+		} `json:"parse"`
+		Error *APIError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", err
+	}
+	if response.Error != nil {
+		if response.Error.Code != "missingtitle" {
+			return "", response.Error
+		}
+		if s.MissingTTL > 0 {
+			return "", s.missing(key)
+		}
+		return "", fmt.Errorf("%w: %s", ErrPageNotFound, key)
+	}
+
+	html := response.Parse.Text
+	s.Cache.SetEntry(htmlKey, key, html, int64(len(html)+64), 0)
+	return html, nil
+}
+
+// InfoboxTables returns the infoboxes of a rendered page: tables with the infobox class and portable
+// infoboxes. Rows with a single header cell followed by a value become fields; a caption or a row
+// with a single header spanning the table becomes the title.
+func InfoboxTables(doc *goquery.Document) []RenderedInfobox {
+	var infoboxes []RenderedInfobox
+
+	doc.Find("table.infobox").Each(func(_ int, table *goquery.Selection) {
+		parsed := ParseHTMLTables(table)
+		infobox := RenderedInfobox{Title: parsed[0].Caption, Fields: make(map[string]string), Table: parsed[0]}
+		table.Find("tr").Each(func(_ int, tr *goquery.Selection) {
+			if tr.Closest("table").Get(0) != table.Get(0) {
+				return
+			}
+			cells := tr.ChildrenFiltered("th, td")
+			switch {
+			case cells.Length() == 1 && goquery.NodeName(cells) == "th" && infobox.Title == "":
+				infobox.Title = cellText(cells)
+			case cells.Length() == 2 && goquery.NodeName(cells.First()) == "th":
+				if label := strings.TrimSuffix(cellText(cells.First()), ":"); label != "" {
+					infobox.Fields[label] = cellText(cells.Last())
+				}
+			}
+		})
+		infoboxes = append(infoboxes, infobox)
+	})
+
+	doc.Find("aside.portable-infobox").Each(func(_ int, aside *goquery.Selection) {
+		infobox := RenderedInfobox{
+			Title:  cellText(aside.Find(".pi-title").First()),
+			Fields: make(map[string]string),
+			Table:  WikiTable{Headers: []string{"Field", "Value"}},
+		}
+		aside.Find(".pi-data").Each(func(_ int, item *goquery.Selection) {
+			label := cellText(item.Find(".pi-data-label"))
+			if label == "" {
+				label = item.AttrOr("data-source", "")
+			}
+			value := item.Find(".pi-data-value")
+			raw, _ := value.Html()
+			infobox.Fields[label] = cellText(value)
+			infobox.Table.Rows = append(infobox.Table.Rows, []TableCell{
+				{Text: label, Raw: label, Header: true},
+				{Text: cellText(value), Raw: strings.TrimSpace(raw)},
+			})
+		})
+		infoboxes = append(infoboxes, infobox)
+	})
+
+	return infoboxes
+}
+
+// Navboxes returns the navigation boxes of a rendered page with the titles of the pages they link to.
+// Links of nested navboxes are listed under the group that contains them.
+func Navboxes(doc *goquery.Document) []Navbox {
+	var navboxes []Navbox
+	doc.Find(".navbox").Not(".navbox .navbox").Each(func(_ int, box *goquery.Selection) {
+		navbox := Navbox{Title: cellText(box.Find(".navbox-title").First())}
+		box.Find(".navbox-list").Not(".navbox-list .navbox-list").Each(func(_ int, list *goquery.Selection) {
+			group := NavboxGroup{Links: linkTitles(list)}
+			if label := list.PrevFiltered(".navbox-group"); label.Length() > 0 {
+				group.Name = cellText(label)
+			}
+			if len(group.Links) > 0 {
+				navbox.Groups = append(navbox.Groups, group)
+			}
+		})
+		navboxes = append(navboxes, navbox)
+	})
+	return navboxes
+}
+
+// Galleries returns the images of every <gallery> on a rendered page, in order.
+func Galleries(doc *goquery.Document) []GalleryImage {
+	var images []GalleryImage
+	doc.Find(".gallerybox").Each(func(_ int, item *goquery.Selection) {
+		file := ""
+		item.Find("a").EachWithBreak(func(_ int, a *goquery.Selection) bool {
+			file = fileTitle(a.AttrOr("href", ""))
+			return file == ""
+		})
+		if file == "" {
+			return
+		}
+		images = append(images, GalleryImage{File: file, Caption: cellText(item.Find(".gallerytext"))})
+	})
+	return images
+}
+
+// cellText returns the whitespace-normalized text of a selection.
+func cellText(sel *goquery.Selection) string {
+	return strings.Join(strings.Fields(sel.Text()), " ")
+}
+
+// linkTitles returns the distinct page titles linked from a selection, in order.
+func linkTitles(sel *goquery.Selection) []string {
+	var titles []string
+	seen := make(map[string]bool)
+	sel.Find("a[title]").Each(func(_ int, a *goquery.Selection) {
+		// Red links are titled "Page (page does not exist)".
+		title := strings.TrimSuffix(a.AttrOr("title", ""), " (page does not exist)")
+		if title == "" || seen[title] || a.HasClass("external") {
+			return
+		}
+		seen[title] = true
+		titles = append(titles, title)
+	})
+	return titles
+}
+
+// fileTitle extracts the "File:" page title from a link such as /wiki/File:Fire_Dragon.png.
+func fileTitle(href string) string {
+	u, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	path := u.Path
+	if title := u.Query().Get("title"); title != "" {
+		path = title
+	}
+	path = path[strings.LastIndex(path, "/")+1:]
+	if !strings.HasPrefix(path, "File:") && !strings.HasPrefix(path, "Image:") {
+		return ""
+	}
+	return strings.ReplaceAll(path, "_", " ")
+}
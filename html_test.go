@@ -0,0 +1,21 @@
+package wizlib
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetRenderedHTMLOffline(t *testing.T) {
+	store, err := NewDiskPageStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(StoredPage{Title: "Fire Hat", Content: "{{ItemInfobox}}"}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewOfflineWikiService(store)
+	if _, err := s.GetRenderedHTML("Fire Hat"); !errors.Is(err, ErrOffline) {
+		t.Errorf("GetRenderedHTML offline: got %v, want ErrOffline", err)
+	}
+}
@@ -2,60 +2,73 @@ package wizlib
 
 import (
 	"context"
-	"errors"
 	"io"
 	"net/http"
-	"sync"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
 	"time"
 
 	cloudflarebp "github.com/DaRealFreak/cloudflare-bp-go"
 )
 
 // APIClient provides methods for making HTTP requests.
+// Its cookie jar keeps session cookies, such as a wiki login, across requests.
 type APIClient struct {
 	Client *http.Client
 }
 
 // NewAPIClient creates a new instance of APIClient.
 func NewAPIClient() *APIClient {
+	jar, _ := cookiejar.New(nil)
 	return &APIClient{
 		Client: &http.Client{
 			Timeout:   10 * time.Second,
 			Transport: cloudflarebp.AddCloudFlareByPass(&http.Transport{}),
+			Jar:       jar,
 		},
 	}
 }
 
 // Get makes a GET request to the specified URL.
 func (c *APIClient) Get(url string) ([]byte, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	bufferPool := sync.Pool{
-		New: func() interface{} {
-			return make([]byte, 32*1024)
-		},
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	buffer := bufferPool.Get().([]byte)
-	defer bufferPool.Put(&buffer)
+	return c.do(req)
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+// Post makes a POST request to the specified URL with a form-encoded body.
+func (c *APIClient) Post(url string, form url.Values) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(form.Encode()))
 	if err != nil {
 		return nil, err
 	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return c.do(req)
+}
+
+// do sends a request and reads the response body.
+func (c *APIClient) do(req *http.Request) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	resp, err := c.Client.Do(req)
+	resp, err := c.Client.Do(req.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
 
 	defer resp.Body.Close()
 
-	n, err := io.ReadFull(resp.Body, buffer)
-	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
-		return nil, err
-	}
+	return io.ReadAll(resp.Body)
+}
 
-	return buffer[:n], nil
+// ensureJar gives the client a cookie jar if it has none, so that sessions survive between requests.
+func (c *APIClient) ensureJar() {
+	if c.Client.Jar == nil {
+		c.Client.Jar, _ = cookiejar.New(nil)
+	}
 }
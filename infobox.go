@@ -0,0 +1,176 @@
+package wizlib
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrNoInfobox is returned when a page does not contain the infobox a typed decoder expects.
+var ErrNoInfobox = errors.New("no matching infobox")
+
+// Schools lists the canonical school names used by the typed decoders.
+var Schools = []string{"Fire", "Ice", "Storm", "Myth", "Life", "Death", "Balance", "Sun", "Moon", "Star", "Shadow"}
+
+// infoboxFields gives tolerant access to template parameters, ignoring case, spaces and punctuation in keys.
+type infoboxFields map[string]string
+
+// newInfoboxFields indexes the parameters of a template by normalized key.
+func newInfoboxFields(t Template) infoboxFields {
+	fields := make(infoboxFields)
+	for _, p := range t.Params {
+		if p.Value != "" {
+			fields[normalizeKey(p.Key)] = p.Value
+		}
+	}
+	return fields
+}
+
+// raw returns the first non-empty value among the given keys.
+func (f infoboxFields) raw(keys ...string) string {
+	for _, key := range keys {
+		if value, ok := f[normalizeKey(key)]; ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// text returns the first non-empty value among the given keys, rendered as plain text.
+func (f infoboxFields) text(keys ...string) string {
+	return plainValue(f.raw(keys...))
+}
+
+// int returns the first integer found in the value of the given keys.
+func (f infoboxFields) int(keys ...string) int {
+	n, _ := parseInt(f.text(keys...))
+	return n
+}
+
+// bool interprets the value of the given keys as a yes/no flag.
+func (f infoboxFields) bool(keys ...string) bool {
+	return parseBool(f.text(keys...))
+}
+
+// list splits the value of the given keys into its items.
+func (f infoboxFields) list(keys ...string) []string {
+	return splitList(f.raw(keys...))
+}
+
+// normalizeKey lower-cases a key and removes everything but letters and digits.
+func normalizeKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, key)
+}
+
+// plainValue renders an infobox value as a single line of plain text.
+func plainValue(value string) string {
+	if value == "" {
+		return ""
+	}
+	return strings.Join(strings.Fields(tableCellRenderer.PlainText(value)), " ")
+}
+
+var (
+	intRegex    = regexp.MustCompile(`[+-]?\d[\d,]*`)
+	numberRegex = regexp.MustCompile(`[+-]?\d[\d,]*(?:\.\d+)?`)
+	listRegex   = regexp.MustCompile(`(?i)<br\s*/?>|\n|\s*[,;•]\s*`)
+)
+
+// parseInt returns the first integer in s, ignoring thousands separators.
+func parseInt(s string) (int, bool) {
+	match := intRegex.FindString(s)
+	if match == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.ReplaceAll(match, ",", ""))
+	return n, err == nil
+}
+
+// parseNumber returns the first decimal number in s.
+func parseNumber(s string) (float64, bool) {
+	match := numberRegex.FindString(s)
+	if match == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(strings.ReplaceAll(match, ",", ""), 64)
+	return n, err == nil
+}
+
+// parseBool interprets the many ways editors write yes and no.
+func parseBool(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "yes", "y", "true", "1", "x", "✓", "✔":
+		return true
+	}
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(s)), "yes")
+}
+
+// parseSchool returns the canonical school named in s, "Any" for unrestricted values, or s itself.
+func parseSchool(s string) string {
+	s = plainValue(s)
+	lower := strings.ToLower(s)
+	switch lower {
+	case "", "none":
+		return ""
+	case "any", "all", "universal", "everyone":
+		return "Any"
+	}
+	for _, school := range Schools {
+		if strings.EqualFold(lower, school) || strings.EqualFold(lower, school+" school") {
+			return school
+		}
+	}
+	return s
+}
+
+// splitList splits a value written as a list, using line breaks, bullets, commas or semicolons.
+// Commas between digits are thousands separators, as in "+1,200 Max Health", and do not split.
+func splitList(value string) []string {
+	var items []string
+	add := func(part string) {
+		part = plainValue(strings.TrimLeft(strings.TrimSpace(part), "*#"))
+		if part != "" {
+			items = append(items, part)
+		}
+	}
+
+	last := 0
+	for _, m := range listRegex.FindAllStringIndex(value, -1) {
+		if value[m[0]:m[1]] == "," && m[0] > 0 && m[1] < len(value) && isDigit(value[m[0]-1]) && isDigit(value[m[1]]) {
+			continue
+		}
+		add(value[last:m[0]])
+		last = m[1]
+	}
+	add(value[last:])
+
+	return items
+}
+
+// isDigit reports whether b is an ASCII digit.
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// linkTargets returns the page titles linked from the wikitext, skipping files and categories.
+func linkTargets(wikiText string) []string {
+	var targets []string
+	for _, m := range internalLinkRegex.FindAllStringSubmatch(wikiText, -1) {
+		target := strings.TrimSpace(strings.TrimPrefix(m[1], ":"))
+		if target == "" || isMediaLink(target) {
+			continue
+		}
+		if i := strings.Index(target, "#"); i >= 0 {
+			target = target[:i]
+		}
+		targets = append(targets, normalizeTitle(target))
+	}
+	return targets
+}
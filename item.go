@@ -0,0 +1,242 @@
+package wizlib
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ItemStat represents a single stat bonus granted by an item.
+type ItemStat struct {
+	Stat    string  `json:"stat"`
+	School  string  `json:"school,omitempty"`
+	Value   float64 `json:"value"`
+	Percent bool    `json:"percent,omitempty"`
+}
+
+// Item represents a piece of gear decoded from an item infobox.
+type Item struct {
+	Name        string            `json:"name"`
+	Slot        string            `json:"slot"`
+	School      string            `json:"school,omitempty"`
+	Level       int               `json:"level"`
+	Stats       []ItemStat        `json:"stats,omitempty"`
+	Sockets     []string          `json:"sockets,omitempty"`
+	Auctionable bool              `json:"auctionable"`
+	Tradeable   bool              `json:"tradeable"`
+	Set         string            `json:"set,omitempty"`
+	Sources     []string          `json:"sources,omitempty"`
+	Infobox     map[string]string `json:"infobox"`
+}
+
+// Stat returns the total value of a stat, optionally restricted to a school.
+func (i *Item) Stat(stat, school string) float64 {
+	total := 0.0
+	for _, s := range i.Stats {
+		if strings.EqualFold(s.Stat, stat) && (school == "" || strings.EqualFold(s.School, school)) {
+			total += s.Value
+		}
+	}
+	return total
+}
+
+// itemInfoboxNames lists the template names used for item pages.
+var itemInfoboxNames = []string{"ItemInfobox", "Item Infobox", "Infobox Item"}
+
+// GetItem retrieves an item page and decodes its infobox.
+func (s *WikiService) GetItem(title string) (*Item, error) {
+	t, wiki, err := s.findInfobox(title, "item")
+	if err != nil {
+		return nil, err
+	}
+	return DecodeItem(wiki.Parse.Title, t), nil
+}
+
+// DecodeItem builds an Item from an item infobox template.
+func DecodeItem(title string, t Template) *Item {
+	f := newInfoboxFields(t)
+
+	item := &Item{
+		Name:    f.text("name"),
+		Slot:    f.text("type", "itemtype", "slot", "kind", "category"),
+		School:  parseSchool(f.raw("school", "schoolreq", "schoolrequirement", "schoolrestriction")),
+		Level:   f.int("level", "levelreq", "levelrequirement", "reqlevel", "minlevel", "requiredlevel"),
+		Set:     f.text("set", "gearset", "itemset", "setbonus"),
+		Infobox: t.Map(),
+	}
+	if item.Name == "" {
+		item.Name = stripNamespace(title)
+	}
+
+	item.Auctionable = flag(f, []string{"auction", "auctionable", "auctionhouse", "bazaar"}, []string{"noauction"})
+	item.Tradeable = flag(f, []string{"trade", "tradeable", "tradable"}, []string{"notrade", "notradeable"})
+
+	item.Sockets = f.list("sockets", "socket", "jewelsockets", "jewels")
+	for n := 1; n <= 6; n++ {
+		if socket := f.text(fmt.Sprintf("socket%d", n), fmt.Sprintf("jewel%d", n)); socket != "" {
+			item.Sockets = append(item.Sockets, socket)
+		}
+	}
+
+	for _, key := range []string{"source", "sources", "dropsfrom", "droppedby", "drops", "vendor", "soldby", "crafted", "recipe", "quest", "reward", "pack", "bundle"} {
+		item.Sources = append(item.Sources, f.list(key)...)
+	}
+
+	// Stats are either separate parameters such as "firedamage" or lines of a free-text bonus field.
+	// Pages that have both repeat the same stats, so a bonus line only adds stats no parameter gave.
+	fromParams := make(map[[2]string]bool)
+	for _, p := range t.Params {
+		key := normalizeKey(p.Key)
+		if ambiguousStatKeys[key] {
+			continue
+		}
+		stat, school, percent, ok := lookupStat(key)
+		if !ok {
+			continue
+		}
+		value, ok := parseNumber(plainValue(p.Value))
+		if !ok || value == 0 {
+			continue
+		}
+		item.Stats = append(item.Stats, ItemStat{
+			Stat:    stat,
+			School:  school,
+			Value:   value,
+			Percent: percent || strings.Contains(p.Value, "%"),
+		})
+		fromParams[[2]string{stat, school}] = true
+	}
+	for _, line := range f.list("bonus", "bonuses", "stats", "statbonus", "attributes") {
+		if stat, ok := parseStatLine(line); ok && !fromParams[[2]string{stat.Stat, stat.School}] {
+			item.Stats = append(item.Stats, stat)
+		}
+	}
+
+	return item
+}
+
+// flag reads a yes/no value that editors may also express through a negated key such as "noauction".
+func flag(f infoboxFields, keys, negated []string) bool {
+	if value := f.text(keys...); value != "" {
+		return parseBool(value)
+	}
+	if value := f.text(negated...); value != "" {
+		return !parseBool(value)
+	}
+	return false
+}
+
+// statDefinition describes a stat an item can grant.
+type statDefinition struct {
+	name    string
+	percent bool
+	school  bool
+}
+
+// statAliases maps normalized stat names to their definitions.
+var statAliases = map[string]statDefinition{
+	"health":              {name: "Max Health"},
+	"maxhealth":           {name: "Max Health"},
+	"mana":                {name: "Max Mana"},
+	"maxmana":             {name: "Max Mana"},
+	"energy":              {name: "Max Energy"},
+	"maxenergy":           {name: "Max Energy"},
+	"powerpip":            {name: "Power Pip Chance", percent: true},
+	"powerpips":           {name: "Power Pip Chance", percent: true},
+	"powerpipchance":      {name: "Power Pip Chance", percent: true},
+	"shadowpip":           {name: "Shadow Pip Rating"},
+	"shadowpiprating":     {name: "Shadow Pip Rating"},
+	"archmastery":         {name: "Archmastery"},
+	"archmasteryrating":   {name: "Archmastery"},
+	"incoming":            {name: "Incoming Healing", percent: true},
+	"incomingheal":        {name: "Incoming Healing", percent: true},
+	"incominghealing":     {name: "Incoming Healing", percent: true},
+	"outgoing":            {name: "Outgoing Healing", percent: true},
+	"outgoingheal":        {name: "Outgoing Healing", percent: true},
+	"outgoinghealing":     {name: "Outgoing Healing", percent: true},
+	"pipconversion":       {name: "Pip Conversion Rating", school: true},
+	"pipconversionrating": {name: "Pip Conversion Rating", school: true},
+	"stunresist":          {name: "Stun Resistance", percent: true},
+	"stunresistance":      {name: "Stun Resistance", percent: true},
+	"fishingluck":         {name: "Fishing Luck", percent: true},
+	"damage":              {name: "Damage", percent: true, school: true},
+	"flatdamage":          {name: "Flat Damage", school: true},
+	"resist":              {name: "Resistance", percent: true, school: true},
+	"resistance":          {name: "Resistance", percent: true, school: true},
+	"flatresist":          {name: "Flat Resistance", school: true},
+	"accuracy":            {name: "Accuracy", percent: true, school: true},
+	"critical":            {name: "Critical Rating", school: true},
+	"criticalrating":      {name: "Critical Rating", school: true},
+	"criticalhit":         {name: "Critical Rating", school: true},
+	"block":               {name: "Critical Block Rating", school: true},
+	"criticalblock":       {name: "Critical Block Rating", school: true},
+	"criticalblockrating": {name: "Critical Block Rating", school: true},
+	"pierce":              {name: "Armor Piercing", percent: true, school: true},
+	"armorpiercing":       {name: "Armor Piercing", percent: true, school: true},
+}
+
+// ambiguousStatKeys are stat names that, as bare infobox parameters without a school prefix,
+// usually describe something else, such as the damage of a card the item gives.
+var ambiguousStatKeys = map[string]bool{
+	"damage":   true,
+	"block":    true,
+	"critical": true,
+	"accuracy": true,
+	"pierce":   true,
+	"resist":   true,
+}
+
+// lookupStat resolves a normalized stat name, which may carry a school prefix such as "firedamage".
+func lookupStat(key string) (stat, school string, percent, ok bool) {
+	if def, found := statAliases[key]; found {
+		return def.name, "", def.percent, true
+	}
+
+	for _, prefix := range append([]string{"universal", "all"}, Schools...) {
+		lower := strings.ToLower(prefix)
+		if !strings.HasPrefix(key, lower) {
+			continue
+		}
+		def, found := statAliases[strings.TrimPrefix(key, lower)]
+		if !found || !def.school {
+			continue
+		}
+		if lower == "universal" || lower == "all" {
+			return def.name, "", def.percent, true
+		}
+		return def.name, prefix, def.percent, true
+	}
+
+	return "", "", false, false
+}
+
+var statLineRegex = regexp.MustCompile(`^([+-]?\d[\d,]*(?:\.\d+)?)\s*(%)?\s*(.+)$`)
+
+// parseStatLine parses a bonus line such as "+5% Fire Damage" or "+120 Max Health".
+func parseStatLine(line string) (ItemStat, bool) {
+	m := statLineRegex.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return ItemStat{}, false
+	}
+
+	value, ok := parseNumber(m[1])
+	if !ok {
+		return ItemStat{}, false
+	}
+
+	stat := ItemStat{Stat: strings.TrimSpace(m[3]), Value: value, Percent: m[2] != ""}
+	if name, school, percent, ok := lookupStat(normalizeKey(m[3])); ok {
+		stat.Stat, stat.School = name, school
+		stat.Percent = stat.Percent || percent
+	}
+
+	return stat, true
+}
+
+// stripNamespace removes a namespace prefix such as "Item:" from a page title.
+func stripNamespace(title string) string {
+	if prefix, name, ok := strings.Cut(title, ":"); ok && prefix != "" && !strings.Contains(prefix, " ") {
+		return strings.TrimSpace(name)
+	}
+	return title
+}
@@ -0,0 +1,32 @@
+package wizlib
+
+import "testing"
+
+func TestDecodeItemStats(t *testing.T) {
+	text := "{{ItemInfobox\n|name = Hat\n|health = 1,200\n|firedamage = 5%\n|damage = 80\n|bonus = +1,200 Max Health<br>+3% Power Pip Chance\n}}"
+	tmpl, ok := FindTemplate(text, itemInfoboxNames...)
+	if !ok {
+		t.Fatal("no infobox")
+	}
+	item := DecodeItem("Item:Hat", tmpl)
+
+	if got := item.Stat("Max Health", ""); got != 1200 {
+		t.Errorf("Max Health = %v, want 1200", got)
+	}
+	if got := item.Stat("Damage", "Fire"); got != 5 {
+		t.Errorf("Fire Damage = %v, want 5", got)
+	}
+	if got := item.Stat("Damage", ""); got != 5 {
+		t.Errorf("Damage = %v, want only the Fire bonus", got)
+	}
+	if got := item.Stat("Power Pip Chance", ""); got != 3 {
+		t.Errorf("Power Pip Chance = %v, want 3", got)
+	}
+}
+
+func TestSplitListThousands(t *testing.T) {
+	got := splitList("+1,200 Max Health, +5% Fire Damage")
+	if len(got) != 2 || got[0] != "+1,200 Max Health" {
+		t.Errorf("splitList() = %q", got)
+	}
+}
@@ -0,0 +1,414 @@
+package wizlib
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Location kinds, from the top of the hierarchy down.
+const (
+	LocationWorld   = "world"
+	LocationArea    = "area"
+	LocationSubArea = "sub-area"
+)
+
+// Location represents a world, area or sub-area.
+type Location struct {
+	Title    string            `json:"title"`
+	Name     string            `json:"name"`
+	Kind     string            `json:"kind"`
+	Parent   string            `json:"parent,omitempty"`
+	World    string            `json:"world,omitempty"`
+	Children []string          `json:"children,omitempty"`
+	Infobox  map[string]string `json:"infobox,omitempty"`
+}
+
+// locationInfoboxNames lists the template names used for location and world pages.
+var locationInfoboxNames = []string{"LocationInfobox", "Location Infobox", "Infobox Location", "WorldInfobox", "AreaInfobox"}
+
+// GetLocation retrieves a location page and decodes its infobox.
+func (s *WikiService) GetLocation(title string) (*Location, error) {
+	t, wiki, err := s.findInfobox(title, "location")
+	if err != nil {
+		return nil, err
+	}
+	return DecodeLocation(wiki.Parse.Title, t), nil
+}
+
+// DecodeLocation builds a Location from a location infobox template.
+// Parent and World hold page titles when the infobox links them, and names otherwise.
+func DecodeLocation(title string, t Template) *Location {
+	f := newInfoboxFields(t)
+
+	location := &Location{
+		Title:   normalizeTitle(title),
+		Name:    f.text("name"),
+		Kind:    strings.ToLower(f.text("kind", "locationtype")),
+		Parent:  firstLinkOrText(f.raw("parent", "area", "location", "zone", "region", "partof")),
+		World:   firstLinkOrText(f.raw("world")),
+		Infobox: t.Map(),
+	}
+	if location.Name == "" {
+		location.Name = stripNamespace(title)
+	}
+	if strings.EqualFold(location.Parent, location.World) {
+		location.Parent = ""
+	}
+	if strings.Contains(strings.ToLower(t.Name), "world") {
+		location.Kind = LocationWorld
+	}
+
+	return location
+}
+
+// LocationTree is a world > area > sub-area hierarchy that resolves free-text location names.
+// Locations whose infobox gives no kind are assigned one from their depth in the tree.
+// It is safe for concurrent use.
+type LocationTree struct {
+	mu        sync.RWMutex
+	locations map[string]*Location
+	aliases   map[string]string
+	// derived holds the locations whose kind was assigned by the tree rather than their infobox.
+	derived map[string]bool
+}
+
+// NewLocationTree creates a new, empty instance of LocationTree.
+func NewLocationTree() *LocationTree {
+	return &LocationTree{
+		locations: make(map[string]*Location),
+		aliases:   make(map[string]string),
+		derived:   make(map[string]bool),
+	}
+}
+
+// AddLocation adds a location to the tree, creating its parent and world when they are not known yet.
+func (t *LocationTree) AddLocation(location *Location) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	location.Title = normalizeTitle(location.Title)
+	if existing, ok := t.locations[location.Title]; ok {
+		location.Children = existing.Children
+		if p, ok := t.locations[existing.Parent]; ok {
+			p.Children = removeString(p.Children, location.Title)
+			// The old parent's branch may have lost its only child.
+			t.updateKinds(t.root(p.Title))
+		}
+	}
+	t.locations[location.Title] = location
+	delete(t.derived, location.Title)
+	t.alias(location.Title, location.Title)
+	t.alias(location.Name, location.Title)
+
+	// Parents and worlds may be given by name; link them to known nodes where possible.
+	if location.World != "" {
+		location.World = t.ensure(location.World, LocationWorld)
+	}
+	if location.Parent != "" {
+		location.Parent = t.ensure(location.Parent, LocationArea)
+
+		// An area only known by name belongs to the same world as its sub-areas.
+		if p := t.locations[location.Parent]; p.Parent == "" && location.World != "" && p.Title != location.World {
+			p.World, p.Parent = location.World, location.World
+			w := t.locations[location.World]
+			if !containsString(w.Children, p.Title) {
+				w.Children = append(w.Children, p.Title)
+				sort.Strings(w.Children)
+			}
+		}
+	}
+
+	parent := location.Parent
+	if parent == "" {
+		parent = location.World
+	}
+	if parent != "" && parent != location.Title {
+		p := t.locations[parent]
+		if !containsString(p.Children, location.Title) {
+			p.Children = append(p.Children, location.Title)
+			sort.Strings(p.Children)
+		}
+		location.Parent = parent
+	}
+
+	t.updateKinds(t.root(location.Title))
+}
+
+// CrawlCategory adds every location page in a category, such as "Wizard City Locations".
+// Pages whose infobox names no world are placed in world, when it is not empty.
+func (t *LocationTree) CrawlCategory(s *WikiService, category, world string) error {
+	titles, err := s.CategoryMembers(category)
+	if err != nil {
+		return err
+	}
+
+	for _, title := range titles {
+		location, err := s.GetLocation(title)
+		if errors.Is(err, ErrPageNotFound) || errors.Is(err, ErrNoInfobox) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if location.World == "" && world != "" && !strings.EqualFold(stripNamespace(title), stripNamespace(world)) {
+			location.World = world
+		}
+		t.AddLocation(location)
+	}
+
+	return nil
+}
+
+// Get returns a location by title.
+func (t *LocationTree) Get(title string) (*Location, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	location, ok := t.locations[normalizeTitle(title)]
+	return location, ok
+}
+
+var locationSplitRegex = regexp.MustCompile(`\s*(?:,|/|>|\s-\s|–|\(|\))\s*`)
+
+// Resolve finds the location named by a free-text value from another infobox, such as
+// "[[Location:Unicorn Way|Unicorn Way]]" or "Wizard City - Unicorn Way".
+// When several parts of the text match, the deepest location wins.
+func (t *LocationTree) Resolve(text string) (*Location, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.resolve(text)
+}
+
+// resolve finds the location named by text; the caller must hold the lock.
+func (t *LocationTree) resolve(text string) (*Location, bool) {
+	var candidates []string
+	candidates = append(candidates, linkTargets(text)...)
+	plain := plainValue(text)
+	candidates = append(candidates, plain)
+	candidates = append(candidates, locationSplitRegex.Split(plain, -1)...)
+
+	var best *Location
+	for _, candidate := range candidates {
+		title, ok := t.aliases[aliasKey(candidate)]
+		if !ok {
+			continue
+		}
+		location := t.locations[title]
+		if best == nil || t.depth(location.Title) > t.depth(best.Title) {
+			best = location
+		}
+	}
+
+	return best, best != nil
+}
+
+// Contains reports whether the location named by text lies within place, or is place itself.
+func (t *LocationTree) Contains(place, text string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.contains(place, text)
+}
+
+// contains reports whether text lies within place; the caller must hold the lock.
+func (t *LocationTree) contains(place, text string) bool {
+	outer, ok := t.resolve(place)
+	if !ok {
+		return false
+	}
+	inner, ok := t.resolve(text)
+	if !ok {
+		return false
+	}
+
+	for title, seen := inner.Title, 0; title != "" && seen <= len(t.locations); seen++ {
+		if title == outer.Title {
+			return true
+		}
+		title = t.locations[title].Parent
+	}
+	return false
+}
+
+// Descendants returns the titles of every location within place.
+func (t *LocationTree) Descendants(place string) []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	location, ok := t.resolve(place)
+	if !ok {
+		return nil
+	}
+
+	var titles []string
+	stack := append([]string(nil), location.Children...)
+	seen := make(map[string]bool)
+	for len(stack) > 0 {
+		title := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[title] {
+			continue
+		}
+		seen[title] = true
+		titles = append(titles, title)
+		stack = append(stack, t.locations[title].Children...)
+	}
+	sort.Strings(titles)
+
+	return titles
+}
+
+// BossesIn returns the bosses of a DropIndex that are located within place, such as "Wizard City".
+func (t *LocationTree) BossesIn(place string, index *DropIndex) []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var bosses []string
+	index.each(func(c *indexedCreature) {
+		if c.Boss && t.creatureIn(place, c) {
+			bosses = append(bosses, c.Title)
+		}
+	})
+	sort.Strings(bosses)
+	return bosses
+}
+
+// DropsIn returns every drop of creatures of a DropIndex located within place, such as "Azteca".
+func (t *LocationTree) DropsIn(place string, index *DropIndex) []DropSource {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var drops []DropSource
+	index.each(func(c *indexedCreature) {
+		if !t.creatureIn(place, c) {
+			return
+		}
+		for _, drop := range c.Drops {
+			drops = append(drops, c.source(drop))
+		}
+	})
+	sort.Slice(drops, func(i, j int) bool {
+		if drops[i].Item != drops[j].Item {
+			return drops[i].Item < drops[j].Item
+		}
+		return drops[i].Creature < drops[j].Creature
+	})
+	return drops
+}
+
+// creatureIn reports whether an indexed creature is located within place; the caller must hold the lock.
+func (t *LocationTree) creatureIn(place string, c *indexedCreature) bool {
+	if c.Location != "" && t.contains(place, c.Location) {
+		return true
+	}
+	return c.World != "" && t.contains(place, c.World)
+}
+
+// ensure returns the title of the location named by ref, creating a placeholder of the given kind if needed.
+func (t *LocationTree) ensure(ref, kind string) string {
+	if title, ok := t.aliases[aliasKey(ref)]; ok {
+		return title
+	}
+
+	title := normalizeTitle(ref)
+	t.locations[title] = &Location{Title: title, Name: stripNamespace(title), Kind: kind}
+	t.derived[title] = true
+	t.alias(title, title)
+	t.alias(stripNamespace(title), title)
+	return title
+}
+
+// alias registers a name that resolves to title.
+func (t *LocationTree) alias(name, title string) {
+	if key := aliasKey(name); key != "" {
+		t.aliases[key] = title
+	}
+	if key := aliasKey(stripNamespace(name)); key != "" {
+		if _, taken := t.aliases[key]; !taken {
+			t.aliases[key] = title
+		}
+	}
+}
+
+// depth returns how many ancestors a location has.
+func (t *LocationTree) depth(title string) int {
+	depth := 0
+	for location, ok := t.locations[title]; ok && location.Parent != "" && depth <= len(t.locations); depth++ {
+		location, ok = t.locations[location.Parent]
+	}
+	return depth
+}
+
+// root returns the topmost ancestor of a location.
+func (t *LocationTree) root(title string) string {
+	for seen := 0; seen <= len(t.locations); seen++ {
+		location, ok := t.locations[title]
+		if !ok || location.Parent == "" {
+			break
+		}
+		title = location.Parent
+	}
+	return title
+}
+
+// updateKinds assigns world, area and sub-area kinds from their depth to a location and everything
+// below it. Kinds given by an infobox are kept. The caller must hold the write lock.
+func (t *LocationTree) updateKinds(top string) {
+	stack := []string{top}
+	seen := make(map[string]bool)
+	for len(stack) > 0 {
+		title := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		location, ok := t.locations[title]
+		if !ok || seen[title] {
+			continue
+		}
+		seen[title] = true
+		stack = append(stack, location.Children...)
+
+		if location.Kind != "" && !t.derived[title] {
+			continue
+		}
+		switch t.depth(title) {
+		case 0:
+			if location.Kind == "" || len(location.Children) > 0 {
+				location.Kind = LocationWorld
+			}
+		case 1:
+			location.Kind = LocationArea
+		default:
+			location.Kind = LocationSubArea
+		}
+		t.derived[title] = true
+	}
+}
+
+// aliasKey normalizes a location name for lookups.
+func aliasKey(name string) string {
+	return strings.ToLower(normalizeTitle(name))
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// removeString returns list without s.
+func removeString(list []string, s string) []string {
+	kept := list[:0]
+	for _, item := range list {
+		if item != s {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,30 @@
+package wizlib
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLocationTreeKinds(t *testing.T) {
+	tree := NewLocationTree()
+	tree.AddLocation(&Location{Title: "Unicorn Way", Name: "Unicorn Way", Parent: "Wizard City"})
+	tree.AddLocation(&Location{Title: "Pet Shop", Name: "Pet Shop", Parent: "Unicorn Way", World: "Wizard City"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tree.Resolve("Wizard City - Unicorn Way")
+			tree.Descendants("Wizard City")
+		}()
+	}
+	wg.Wait()
+
+	for title, want := range map[string]string{"Wizard City": LocationWorld, "Unicorn Way": LocationArea, "Pet Shop": LocationSubArea} {
+		location, ok := tree.Get(title)
+		if !ok || location.Kind != want {
+			t.Errorf("Get(%q) = %+v, want kind %q", title, location, want)
+		}
+	}
+}
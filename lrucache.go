@@ -0,0 +1,203 @@
+package wizlib
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// CacheOptions configures an LRUCache. Zero values remove the corresponding limit.
+type CacheOptions struct {
+	MaxEntries int
+	MaxBytes   int64
+	TTL        time.Duration
+}
+
+// DefaultCacheOptions are the cache limits used by NewWikiService.
+var DefaultCacheOptions = CacheOptions{
+	MaxEntries: 2000,
+	MaxBytes:   64 << 20,
+	TTL:        time.Hour,
+}
+
+// CacheStats reports the usage of an LRUCache.
+type CacheStats struct {
+	Entries   int    `json:"entries"`
+	Bytes     int64  `json:"bytes"`
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Expired   uint64 `json:"expired"`
+}
+
+// lruEntry is a cached value with the page title it belongs to.
+type lruEntry struct {
+	key     string
+	title   string
+	value   interface{}
+	size    int64
+	expires time.Time
+}
+
+// LRUCache is a size-bounded cache that evicts the least recently used entries and expires entries after a TTL.
+// Entries may belong to a page title, so that everything cached for a page can be invalidated at once.
+// A nil *LRUCache is valid and caches nothing.
+type LRUCache struct {
+	mu      sync.Mutex
+	opts    CacheOptions
+	order   *list.List
+	items   map[string]*list.Element
+	byTitle map[string]map[string]bool
+	bytes   int64
+	stats   CacheStats
+}
+
+// NewLRUCache creates a new, empty instance of LRUCache.
+func NewLRUCache(opts CacheOptions) *LRUCache {
+	return &LRUCache{
+		opts:    opts,
+		order:   list.New(),
+		items:   make(map[string]*list.Element),
+		byTitle: make(map[string]map[string]bool),
+	}
+}
+
+// Get returns a cached value that has not expired.
+func (c *LRUCache) Get(key string) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	entry := el.Value.(*lruEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		c.remove(el)
+		c.stats.Expired++
+		c.stats.Misses++
+		return nil, false
+	}
+
+	c.order.MoveToFront(el)
+	c.stats.Hits++
+	return entry.value, true
+}
+
+// Set caches a value belonging to the page with the same title as key, using the default TTL.
+func (c *LRUCache) Set(key string, value interface{}, size int64) {
+	c.SetEntry(key, key, value, size, 0)
+}
+
+// SetEntry caches a value belonging to a page title, which may be empty. A zero ttl uses the cache's TTL.
+// Values larger than MaxBytes are not cached, and remove any value previously cached under key.
+func (c *LRUCache) SetEntry(key, title string, value interface{}, size int64, ttl time.Duration) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+	if c.opts.MaxBytes > 0 && size > c.opts.MaxBytes {
+		return
+	}
+
+	if ttl == 0 {
+		ttl = c.opts.TTL
+	}
+	entry := &lruEntry{key: key, title: normalizeTitle(title), value: value, size: size}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+
+	c.items[key] = c.order.PushFront(entry)
+	c.bytes += size
+	if entry.title != "" {
+		if c.byTitle[entry.title] == nil {
+			c.byTitle[entry.title] = make(map[string]bool)
+		}
+		c.byTitle[entry.title][key] = true
+	}
+
+	for c.order.Len() > 0 && (c.opts.MaxEntries > 0 && c.order.Len() > c.opts.MaxEntries || c.opts.MaxBytes > 0 && c.bytes > c.opts.MaxBytes) {
+		c.remove(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+// Delete removes a single entry.
+func (c *LRUCache) Delete(key string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+}
+
+// Invalidate removes every entry belonging to a page title.
+func (c *LRUCache) Invalidate(title string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.byTitle[normalizeTitle(title)] {
+		if el, ok := c.items[key]; ok {
+			c.remove(el)
+		}
+	}
+}
+
+// Clear removes every entry; statistics are kept.
+func (c *LRUCache) Clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+	c.byTitle = make(map[string]map[string]bool)
+	c.bytes = 0
+}
+
+// Stats returns the current usage of the cache.
+func (c *LRUCache) Stats() CacheStats {
+	if c == nil {
+		return CacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.order.Len()
+	stats.Bytes = c.bytes
+	return stats
+}
+
+// remove drops an entry; the caller must hold mu.
+func (c *LRUCache) remove(el *list.Element) {
+	entry := el.Value.(*lruEntry)
+	c.order.Remove(el)
+	delete(c.items, entry.key)
+	c.bytes -= entry.size
+	if keys := c.byTitle[entry.title]; keys != nil {
+		delete(keys, entry.key)
+		if len(keys) == 0 {
+			delete(c.byTitle, entry.title)
+		}
+	}
+}
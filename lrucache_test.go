@@ -0,0 +1,13 @@
+package wizlib
+
+import "testing"
+
+func TestLRUCacheOversizedOverwrite(t *testing.T) {
+	c := NewLRUCache(CacheOptions{MaxBytes: 100})
+	c.Set("Fire Hat", "old", 10)
+	c.Set("Fire Hat", "new", 200)
+
+	if value, ok := c.Get("Fire Hat"); ok {
+		t.Errorf("Get after oversized overwrite = %v, want no entry", value)
+	}
+}
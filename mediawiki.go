@@ -2,16 +2,21 @@ package wizlib
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
+	"net/url"
 	"regexp"
 	"strings"
 	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 type WikiResponse struct {
 	Parse struct {
 		Title   string   `json:"title"`
 		PageID  int64    `json:"pageid"`
+		RevID   int64    `json:"revid"`
 		Images  []string `json:"images"`
 		Content string   `json:"wikitext"`
 	} `json:"parse"`
@@ -21,37 +26,161 @@ const apiURL = "https://wiki.wizard101central.com/wiki/api.php"
 
 type WikiService struct {
 	Client *APIClient
-	cache  sync.Map
+	Source PageSource
+	// Endpoint is the URL of the wiki's api.php; the profile's endpoint, or the Wizard101 Central wiki, is used when empty.
+	Endpoint string
+	// Profile describes the wiki's infobox templates; nil means the Wizard101 Central wiki.
+	Profile *WikiProfile
+	// Cache holds fetched pages and other responses; nil disables caching.
+	Cache *LRUCache
+	// MissingTTL is how long a missing page is remembered, with its suggestions; zero disables it.
+	MissingTTL time.Duration
+
+	hooksMu sync.RWMutex
+	hooks   []func(StoredPage)
+
+	expandMu  sync.RWMutex
+	expand    map[string][]string
+	expandGen int
+
+	authMu      sync.Mutex
+	credentials *credentials
 }
 
 func NewWikiService(client *APIClient) *WikiService {
-	return &WikiService{Client: client}
+	s := &WikiService{Client: client, Cache: NewLRUCache(DefaultCacheOptions), MissingTTL: 5 * time.Minute}
+	source := NewLiveSource(client)
+	source.query = s.query
+	s.Source = source
+	return s
 }
 
-func (s *WikiService) GetWikiText(pageName string) (WikiResponse, error) {
-	url := fmt.Sprintf("%s?action=parse&page=%s&prop=wikitext|images&formatversion=2&format=json", apiURL, pageName)
+// ErrOffline is returned by methods that need the wiki API when the service has no APIClient.
+var ErrOffline = errors.New("mediawiki: service is offline")
 
-	// Check cache first
-	if cachedResponse, ok := s.cache.Load(url); ok {
-		return cachedResponse.(WikiResponse), nil
-	}
+// NewOfflineWikiService creates a WikiService that serves pages from a local store without calling the API.
+// Methods that read pages through the source, such as GetWikiText, ParseToJSON and the typed getters
+// like GetItem and GetCreature, work offline. Methods that need the API, such as CategoryMembers,
+// GetRevision, GetRenderedHTML, Suggest, Login and Edit, fail with ErrOffline, as do a Crawler
+// or RecentChangesWatcher using the service.
+func NewOfflineWikiService(store *DiskPageStore) *WikiService {
+	return &WikiService{Source: store, Cache: NewLRUCache(DefaultCacheOptions), MissingTTL: 5 * time.Minute}
+}
 
-	body, err := s.Client.Get(url)
+// GetWikiText retrieves a page, with the templates selected with ExpandTemplatesIn expanded.
+// If the expansion fails, the page is returned as stored on the wiki.
+func (s *WikiService) GetWikiText(pageName string) (WikiResponse, error) {
+	response, err := s.GetRawWikiText(pageName)
 	if err != nil {
 		return WikiResponse{}, err
 	}
+	return s.expandResponse(response), nil
+}
+
+// GetRawWikiText retrieves a page as stored on the wiki, without expanding any templates.
+func (s *WikiService) GetRawWikiText(pageName string) (WikiResponse, error) {
+	// Check cache first
+	key := normalizeTitle(pageName)
+	if cached, ok := s.Cache.Get(key); ok {
+		switch cached := cached.(type) {
+		case WikiResponse:
+			return cached, nil
+		case *MissingPageError:
+			return WikiResponse{}, cached
+		}
+	}
 
-	var response WikiResponse
-	if err := json.Unmarshal(body, &response); err != nil {
+	page, err := s.Source.FetchPage(pageName)
+	if errors.Is(err, ErrPageNotFound) && s.MissingTTL > 0 {
+		return WikiResponse{}, s.missing(key)
+	}
+	if err != nil {
 		return WikiResponse{}, err
 	}
+	response := page.Response()
+	s.notify(page)
 
 	// Cache the response
-	s.cache.Store(url, response)
+	s.Cache.SetEntry(key, key, response, responseSize(response), 0)
 
 	return response, nil
 }
 
+// Invalidate removes everything cached for the given page.
+func (s *WikiService) Invalidate(pageName string) {
+	s.Cache.Invalidate(normalizeTitle(pageName))
+}
+
+// responseSize estimates the memory held by a cached response.
+func responseSize(r WikiResponse) int64 {
+	size := int64(len(r.Parse.Title) + len(r.Parse.Content) + 64)
+	for _, image := range r.Parse.Images {
+		size += int64(len(image)) + 16
+	}
+	return size
+}
+
+// OnFetch registers a function that is called with every page fetched from the source.
+// Pages served from the cache are not reported again.
+func (s *WikiService) OnFetch(fn func(StoredPage)) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+
+	s.hooks = append(s.hooks, fn)
+}
+
+// notify calls the registered fetch hooks.
+func (s *WikiService) notify(page StoredPage) {
+	s.hooksMu.RLock()
+	defer s.hooksMu.RUnlock()
+
+	for _, fn := range s.hooks {
+		fn(page)
+	}
+}
+
+// query performs a GET request against the wiki API with the given parameters.
+func (s *WikiService) query(params url.Values) ([]byte, error) {
+	return s.call(params, false)
+}
+
+// post performs a POST request against the wiki API with the given parameters.
+func (s *WikiService) post(params url.Values) ([]byte, error) {
+	return s.call(params, true)
+}
+
+// send performs a single API request, failing with ErrOffline when the service has no client.
+func (s *WikiService) send(params url.Values, post bool) ([]byte, error) {
+	if s.Client == nil {
+		return nil, ErrOffline
+	}
+	params.Set("format", "json")
+	params.Set("formatversion", "2")
+
+	endpoint := s.Endpoint
+	if endpoint == "" && s.Profile != nil {
+		endpoint = s.Profile.Endpoint
+	}
+	if endpoint == "" {
+		endpoint = apiURL
+	}
+	if post {
+		return s.Client.Post(endpoint, params)
+	}
+	return s.Client.Get(endpoint + "?" + params.Encode())
+}
+
+// normalizeTitle converts a page name into the canonical form used by MediaWiki,
+// so that "fire_dragon" and "Fire Dragon" refer to the same page.
+func normalizeTitle(pageName string) string {
+	title := strings.Join(strings.Fields(strings.ReplaceAll(pageName, "_", " ")), " ")
+	if title == "" {
+		return ""
+	}
+	first, size := utf8.DecodeRuneInString(title)
+	return string(unicode.ToUpper(first)) + title[size:]
+}
+
 func (s *WikiService) ParseToJSON(pageName string) ([]byte, error) {
 	wiki, err := s.GetWikiText(pageName)
 	if err != nil {
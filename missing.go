@@ -0,0 +1,122 @@
+package wizlib
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// MissingPageError is returned for a page that does not exist. Similar titles found by the wiki's
+// search are available from Suggestions. It matches ErrPageNotFound with errors.Is.
+type MissingPageError struct {
+	Title string
+
+	service     *WikiService
+	mu          sync.Mutex
+	loaded      bool
+	suggestions []string
+}
+
+// Suggestions returns up to five titles similar to the missing one. The wiki is only searched on the
+// first call, so callers that just check for ErrPageNotFound never pay for it. Search errors and
+// offline services yield no suggestions.
+func (e *MissingPageError) Suggestions() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.loaded {
+		e.loaded = true
+		if e.service != nil && e.service.Client != nil {
+			e.suggestions, _ = e.service.Suggest(e.Title, 5)
+		}
+	}
+	return e.suggestions
+}
+
+// Error describes the missing page, with its suggestions once Suggestions has loaded them.
+func (e *MissingPageError) Error() string {
+	e.mu.Lock()
+	suggestions := e.suggestions
+	e.mu.Unlock()
+
+	if len(suggestions) == 0 {
+		return fmt.Sprintf("%s: %s", ErrPageNotFound, e.Title)
+	}
+	return fmt.Sprintf("%s: %s (did you mean %s?)", ErrPageNotFound, e.Title, strings.Join(suggestions, ", "))
+}
+
+func (e *MissingPageError) Unwrap() error {
+	return ErrPageNotFound
+}
+
+// MarshalJSON encodes the title and the suggestions, loading them if needed.
+func (e *MissingPageError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Title       string   `json:"title"`
+		Suggestions []string `json:"suggestions,omitempty"`
+	}{e.Title, e.Suggestions()})
+}
+
+// missing builds the error for a missing page and remembers it for MissingTTL, so that repeated
+// lookups of the same typo are answered without calling the API.
+func (s *WikiService) missing(title string) *MissingPageError {
+	missing := &MissingPageError{Title: title, service: s}
+	// Room for the suggestions, which may be loaded after the entry is stored.
+	s.Cache.SetEntry(title, title, missing, int64(len(title)+64+5*48), s.MissingTTL)
+	return missing
+}
+
+// Suggest returns up to limit page titles similar to query, using the wiki's search. When the search
+// finds nothing but offers a spelling correction, the correction is searched instead.
+func (s *WikiService) Suggest(query string, limit int) ([]string, error) {
+	titles, correction, err := s.search(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(titles) == 0 && correction != "" && !strings.EqualFold(correction, query) {
+		titles, _, err = s.search(correction, limit)
+	}
+	return titles, err
+}
+
+// search runs a list=search query and returns the titles found and the spelling correction, if any.
+func (s *WikiService) search(query string, limit int) ([]string, string, error) {
+	params := url.Values{}
+	params.Set("action", "query")
+	params.Set("list", "search")
+	params.Set("srsearch", query)
+	params.Set("srlimit", fmt.Sprint(limit))
+	params.Set("srinfo", "suggestion")
+	params.Set("srprop", "")
+
+	body, err := s.query(params)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var response struct {
+		Query struct {
+			SearchInfo struct {
+				Suggestion string `json:"suggestion"`
+			} `json:"searchinfo"`
+			Search []struct {
+				Title string `json:"title"`
+			} `json:"search"`
+		} `json:"query"`
+		Error *APIError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, "", err
+	}
+	if response.Error != nil {
+		return nil, "", response.Error
+	}
+
+	titles := make([]string, 0, len(response.Query.Search))
+	for _, result := range response.Query.Search {
+		titles = append(titles, result.Title)
+	}
+	return titles, response.Query.SearchInfo.Suggestion, nil
+}
@@ -0,0 +1,42 @@
+package wizlib
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMissingPageSuggestionsAreLazy(t *testing.T) {
+	w, s := newFakeWiki(t)
+
+	_, err := s.GetWikiText("Fire Hatt")
+	if !errors.Is(err, ErrPageNotFound) {
+		t.Fatalf("GetWikiText: got %v, want ErrPageNotFound", err)
+	}
+	if w.searches != 0 {
+		t.Fatalf("searches after a lookup: got %d, want 0", w.searches)
+	}
+
+	var missing *MissingPageError
+	if !errors.As(err, &missing) {
+		t.Fatalf("GetWikiText: got %T, want *MissingPageError", err)
+	}
+	want := []string{"Fire Hat"}
+	for i := 0; i < 2; i++ {
+		if got := missing.Suggestions(); !reflect.DeepEqual(got, want) {
+			t.Errorf("Suggestions: got %q, want %q", got, want)
+		}
+	}
+	if w.searches != 1 {
+		t.Errorf("searches: got %d, want 1", w.searches)
+	}
+
+	data, err := json.Marshal(missing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != `{"title":"Fire Hatt","suggestions":["Fire Hat"]}` {
+		t.Errorf("MarshalJSON: got %s", got)
+	}
+}
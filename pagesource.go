@@ -0,0 +1,185 @@
+package wizlib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// PageSource provides wiki pages to a WikiService.
+type PageSource interface {
+	FetchPage(title string) (StoredPage, error)
+}
+
+// APIError represents an error reported by the MediaWiki API.
+type APIError struct {
+	Code string `json:"code"`
+	Info string `json:"info"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("mediawiki: %s: %s", e.Code, e.Info)
+}
+
+// LiveSource fetches pages from the wiki API.
+// The source created by NewWikiService sends its requests through the service instead,
+// so that they share its endpoint and login session.
+type LiveSource struct {
+	Client   *APIClient
+	Endpoint string
+
+	query func(params url.Values) ([]byte, error)
+}
+
+// NewLiveSource creates a new instance of LiveSource for the Wizard101 Central wiki.
+func NewLiveSource(client *APIClient) *LiveSource {
+	return &LiveSource{
+		Client:   client,
+		Endpoint: apiURL,
+	}
+}
+
+// FetchPage retrieves the current wikitext and images of a page through action=parse.
+func (s *LiveSource) FetchPage(title string) (StoredPage, error) {
+	params := url.Values{}
+	params.Set("action", "parse")
+	params.Set("page", title)
+	params.Set("prop", "wikitext|images")
+
+	var body []byte
+	var err error
+	if s.query != nil {
+		body, err = s.query(params)
+	} else {
+		params.Set("formatversion", "2")
+		params.Set("format", "json")
+		body, err = s.Client.Get(s.Endpoint + "?" + params.Encode())
+	}
+	if err != nil {
+		return StoredPage{}, err
+	}
+
+	var response struct {
+		WikiResponse
+		Error *APIError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return StoredPage{}, err
+	}
+	if response.Error != nil {
+		if response.Error.Code == "missingtitle" {
+			return StoredPage{}, fmt.Errorf("%w: %s", ErrPageNotFound, title)
+		}
+		return StoredPage{}, response.Error
+	}
+
+	parse := response.Parse
+	return StoredPage{
+		Title:   parse.Title,
+		PageID:  parse.PageID,
+		RevID:   parse.RevID,
+		Images:  parse.Images,
+		Content: parse.Content,
+		Infobox: extractInfoboxData(extractInfobox(parse.Content)),
+		Fetched: time.Now().UTC(),
+	}, nil
+}
+
+// FetchPage retrieves a page from the store, implementing PageSource.
+func (s *DiskPageStore) FetchPage(title string) (StoredPage, error) {
+	return s.Get(title)
+}
+
+// LayeredSource serves pages from a local store first and falls back to a remote source.
+// Pages fetched remotely are written to the local store, so a restarted bot starts warm.
+type LayeredSource struct {
+	Local  *DiskPageStore
+	Remote PageSource
+	// MaxAge makes local copies older than this be refreshed from Remote; zero keeps them forever.
+	MaxAge time.Duration
+	// OnStoreError, if set, is called when a remotely fetched page cannot be written to Local.
+	// The page is returned either way.
+	OnStoreError func(title string, err error)
+}
+
+// NewLayeredSource creates a new instance of LayeredSource.
+func NewLayeredSource(local *DiskPageStore, remote PageSource) *LayeredSource {
+	return &LayeredSource{
+		Local:  local,
+		Remote: remote,
+	}
+}
+
+// FetchPage returns the local copy of a page if it is fresh, otherwise fetches it remotely.
+// A stale local copy is still returned when the remote source cannot be reached.
+func (s *LayeredSource) FetchPage(title string) (StoredPage, error) {
+	local, localErr := s.Local.Get(title)
+	if localErr == nil && !s.stale(local) {
+		return local, nil
+	}
+
+	page, err := s.Remote.FetchPage(title)
+	if err != nil {
+		if localErr == nil && !errors.Is(err, ErrPageNotFound) {
+			return local, nil
+		}
+		return StoredPage{}, err
+	}
+
+	if err := s.Local.Put(page); err != nil && s.OnStoreError != nil {
+		s.OnStoreError(page.Title, err)
+	}
+
+	return page, nil
+}
+
+// Refresh fetches a page from the remote source, bypassing the local copy, and updates the local
+// store with it. A page that no longer exists is removed from the local store.
+func (s *LayeredSource) Refresh(title string) (StoredPage, error) {
+	page, err := s.Remote.FetchPage(title)
+	if errors.Is(err, ErrPageNotFound) {
+		s.Local.Delete(title)
+		return StoredPage{}, err
+	}
+	if err != nil {
+		return StoredPage{}, err
+	}
+
+	if err := s.Local.Put(page); err != nil && s.OnStoreError != nil {
+		s.OnStoreError(page.Title, err)
+	}
+
+	return page, nil
+}
+
+// fetchCurrent retrieves the current revision of a page, bypassing the cache and refreshing the
+// local copy of a LayeredSource. Fetch hooks see the page as with GetWikiText.
+func (s *WikiService) fetchCurrent(title string) (StoredPage, error) {
+	s.Invalidate(title)
+
+	var page StoredPage
+	var err error
+	if layered, ok := s.Source.(*LayeredSource); ok {
+		page, err = layered.Refresh(title)
+	} else {
+		page, err = s.Source.FetchPage(title)
+	}
+	if err != nil {
+		return StoredPage{}, err
+	}
+
+	s.notify(page)
+	return page, nil
+}
+
+// stale reports whether a local copy should be refreshed.
+func (s *LayeredSource) stale(page StoredPage) bool {
+	fetched := page.Fetched
+	if fetched.IsZero() {
+		// Pages imported from a dump only carry their revision timestamp.
+		fetched = page.Timestamp
+	}
+	return s.MaxAge > 0 && time.Since(fetched) > s.MaxAge
+}
@@ -0,0 +1,147 @@
+package wizlib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrPageNotFound is returned when a page does not exist in a page store or on the wiki.
+var ErrPageNotFound = errors.New("page not found")
+
+// StoredPage represents a wiki page kept in a local page store.
+type StoredPage struct {
+	Title     string            `json:"title"`
+	PageID    int64             `json:"pageid"`
+	Namespace int               `json:"ns"`
+	RevID     int64             `json:"revid"`
+	Timestamp time.Time         `json:"timestamp"`
+	Fetched   time.Time         `json:"fetched"`
+	Images    []string          `json:"images,omitempty"`
+	Content   string            `json:"wikitext"`
+	Infobox   map[string]string `json:"infobox,omitempty"`
+}
+
+// Response converts the stored page into the shape returned by the parse API.
+func (p StoredPage) Response() WikiResponse {
+	var response WikiResponse
+	response.Parse.Title = p.Title
+	response.Parse.PageID = p.PageID
+	response.Parse.RevID = p.RevID
+	response.Parse.Images = p.Images
+	response.Parse.Content = p.Content
+	return response
+}
+
+// DiskPageStore stores wiki pages as JSON files, one file per normalized title. Files are named
+// after a hash of the title, which is kept inside the file, so that long titles fit the file
+// system's name limit and titles differing only in case do not collide.
+type DiskPageStore struct {
+	Dir string
+}
+
+// NewDiskPageStore creates a new instance of DiskPageStore, creating dir if needed.
+func NewDiskPageStore(dir string) (*DiskPageStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DiskPageStore{Dir: dir}, nil
+}
+
+// Get retrieves a page by title.
+func (s *DiskPageStore) Get(title string) (StoredPage, error) {
+	data, err := os.ReadFile(s.path(title))
+	if errors.Is(err, os.ErrNotExist) {
+		return StoredPage{}, ErrPageNotFound
+	}
+	if err != nil {
+		return StoredPage{}, err
+	}
+
+	var page StoredPage
+	if err := json.Unmarshal(data, &page); err != nil {
+		return StoredPage{}, err
+	}
+
+	return page, nil
+}
+
+// Put stores a page, replacing any previous version with the same title.
+func (s *DiskPageStore) Put(page StoredPage) error {
+	page.Title = normalizeTitle(page.Title)
+
+	data, err := json.Marshal(page)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(s.path(page.Title), data)
+}
+
+// Delete removes a page from the store.
+func (s *DiskPageStore) Delete(title string) error {
+	err := os.Remove(s.path(title))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Titles returns the sorted titles of all stored pages.
+func (s *DiskPageStore) Titles() ([]string, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.Dir, name))
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Title string `json:"title"`
+		}
+		if json.Unmarshal(data, &page) != nil || page.Title == "" {
+			continue
+		}
+		titles = append(titles, page.Title)
+	}
+	sort.Strings(titles)
+
+	return titles, nil
+}
+
+// path returns the file path used for the given title.
+func (s *DiskPageStore) path(title string) string {
+	sum := sha256.Sum256([]byte(normalizeTitle(title)))
+	return filepath.Join(s.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+var imageLinkRegex = regexp.MustCompile(`(?i)\[\[\s*(?:file|image)\s*:\s*([^|\]]+)`)
+
+// extractImages returns the file names linked from the wikitext, in order of appearance.
+func extractImages(wikiText string) []string {
+	var images []string
+	seen := make(map[string]bool)
+	for _, match := range imageLinkRegex.FindAllStringSubmatch(wikiText, -1) {
+		name := strings.ReplaceAll(strings.TrimSpace(match[1]), " ", "_")
+		if name != "" && !seen[name] {
+			seen[name] = true
+			images = append(images, name)
+		}
+	}
+	return images
+}
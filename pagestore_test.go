@@ -0,0 +1,38 @@
+package wizlib
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiskPageStoreTitles(t *testing.T) {
+	store, err := NewDiskPageStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	long := strings.Repeat("Very Long Quest Name ", 20)
+	for _, title := range []string{"élan vital", "Fire dragon", "Fire Dragon", long} {
+		if err := store.Put(StoredPage{Title: title, Content: title}); err != nil {
+			t.Fatalf("Put(%q): %v", title, err)
+		}
+	}
+
+	titles, err := store.Titles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Fire Dragon", "Fire dragon", normalizeTitle(long), "Élan vital"}
+	if !reflect.DeepEqual(titles, want) {
+		t.Errorf("Titles() = %q, want %q", titles, want)
+	}
+
+	page, err := store.Get("fire_dragon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Content != "Fire dragon" {
+		t.Errorf("Get(fire_dragon).Content = %q, want %q", page.Content, "Fire dragon")
+	}
+}
@@ -3,6 +3,7 @@ package wizlib
 import (
 	"fmt"
 	"math"
+	"strings"
 )
 
 type PetAttributes struct {
@@ -81,3 +82,137 @@ func (c *PetCalculator) bitsetDiv(att1, att2, att3 int64, offset float64) string
 func CompareFactors(petOne, petTwo int64) int64 {
 	return (100 * (11 - petOne) / (22 - (petOne + petTwo)))
 }
+
+// PetLevels lists the pet levels in the order a pet reaches them.
+var PetLevels = []string{"Baby", "Teen", "Adult", "Ancient", "Epic", "Mega", "Ultra"}
+
+// Pet represents a pet decoded from a pet infobox.
+type Pet struct {
+	Name         string                   `json:"name"`
+	Body         string                   `json:"body,omitempty"`
+	School       string                   `json:"school,omitempty"`
+	MaxStats     PetAttributes            `json:"max_stats"`
+	StatsByLevel map[string]PetAttributes `json:"stats_by_level,omitempty"`
+	Talents      []string                 `json:"talents,omitempty"`
+	Derby        []string                 `json:"derby,omitempty"`
+	Infobox      map[string]string        `json:"infobox"`
+}
+
+// Attributes returns the maximum stats of the pet at a level such as "Adult", ready for PetCalculator.Calculate.
+// Levels the infobox does not list, and the empty level, use the overall maximum stats.
+func (p *Pet) Attributes(level string) *PetAttributes {
+	for name, stats := range p.StatsByLevel {
+		if strings.EqualFold(name, level) {
+			return &stats
+		}
+	}
+	stats := p.MaxStats
+	return &stats
+}
+
+// petInfoboxNames lists the template names used for pet pages.
+var petInfoboxNames = []string{"PetInfobox", "Pet Infobox", "Infobox Pet"}
+
+// petStatKeys lists the infobox keys used for each pet stat.
+var petStatKeys = struct {
+	strength, intellect, agility, will, power, happiness []string
+}{
+	strength:  []string{"strength", "str"},
+	intellect: []string{"intellect", "intelligence", "int"},
+	agility:   []string{"agility", "agi"},
+	will:      []string{"will", "willpower"},
+	power:     []string{"power", "pow"},
+	happiness: []string{"happiness", "maxhappiness"},
+}
+
+// GetPet retrieves a pet page and decodes its infobox.
+func (s *WikiService) GetPet(title string) (*Pet, error) {
+	t, wiki, err := s.findInfobox(title, "pet")
+	if err != nil {
+		return nil, err
+	}
+	return DecodePet(wiki.Parse.Title, t), nil
+}
+
+// DecodePet builds a Pet from a pet infobox template.
+// Talents and derby abilities keep the order of the infobox, which is the order the pet can manifest them.
+func DecodePet(title string, t Template) *Pet {
+	f := newInfoboxFields(t)
+
+	pet := &Pet{
+		Name:     f.text("name"),
+		Body:     f.text("body", "bodytype", "type"),
+		School:   parseSchool(f.raw("school")),
+		MaxStats: petStats(f, ""),
+		Talents:  petAbilities(f, "talent", "talents", "talentpool"),
+		Derby:    petAbilities(f, "derby", "derbies", "derbyabilities", "derbypool"),
+		Infobox:  t.Map(),
+	}
+	if pet.Name == "" {
+		pet.Name = stripNamespace(title)
+	}
+
+	for _, level := range PetLevels {
+		stats := petStats(f, strings.ToLower(level))
+		if stats == (PetAttributes{}) {
+			continue
+		}
+		if pet.StatsByLevel == nil {
+			pet.StatsByLevel = make(map[string]PetAttributes)
+		}
+		pet.StatsByLevel[level] = stats
+	}
+
+	// Some infoboxes only list stats per level; the highest level listed is then the maximum.
+	if pet.MaxStats == (PetAttributes{}) {
+		for _, level := range PetLevels {
+			if stats, ok := pet.StatsByLevel[level]; ok {
+				pet.MaxStats = stats
+			}
+		}
+	}
+
+	return pet
+}
+
+// petStats reads the stats for a level, written as "babystrength" or "strengthbaby", or the unprefixed stats for no level.
+func petStats(f infoboxFields, level string) PetAttributes {
+	stat := func(names []string) int64 {
+		keys := make([]string, 0, 2*len(names))
+		for _, name := range names {
+			if level == "" {
+				keys = append(keys, name, "max"+name)
+			} else {
+				keys = append(keys, level+name, name+level)
+			}
+		}
+		return int64(f.int(keys...))
+	}
+
+	return PetAttributes{
+		Strength:     stat(petStatKeys.strength),
+		Willpower:    stat(petStatKeys.will),
+		Intelligence: stat(petStatKeys.intellect),
+		Power:        stat(petStatKeys.power),
+		Agility:      stat(petStatKeys.agility),
+		Happiness:    stat(petStatKeys.happiness),
+	}
+}
+
+// petAbilities reads numbered parameters such as "talent1" through "talent10", or a single list parameter.
+func petAbilities(f infoboxFields, prefix string, listKeys ...string) []string {
+	var abilities []string
+	for n := 1; n <= 12; n++ {
+		if ability := firstLinkOrText(f.raw(fmt.Sprintf("%s%d", prefix, n))); ability != "" {
+			abilities = append(abilities, stripNamespace(ability))
+		}
+	}
+	if len(abilities) > 0 {
+		return abilities
+	}
+
+	for _, ability := range f.list(listKeys...) {
+		abilities = append(abilities, stripNamespace(ability))
+	}
+	return abilities
+}
@@ -0,0 +1,217 @@
+package wizlib
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PirateClasses lists the canonical Pirate101 class names.
+var PirateClasses = []string{"Buccaneer", "Privateer", "Witchdoctor", "Musketeer", "Swashbuckler"}
+
+// PirateStats holds the Pirate101 combat stats of a companion or the bonuses of an item.
+type PirateStats struct {
+	Strength int `json:"strength,omitempty"`
+	Agility  int `json:"agility,omitempty"`
+	Will     int `json:"will,omitempty"`
+	Health   int `json:"health,omitempty"`
+	Armor    int `json:"armor,omitempty"`
+	Accuracy int `json:"accuracy,omitempty"`
+	Dodge    int `json:"dodge,omitempty"`
+}
+
+// Companion represents a Pirate101 companion decoded from a companion infobox.
+type Companion struct {
+	Name        string            `json:"name"`
+	Class       string            `json:"class"`
+	Species     string            `json:"species,omitempty"`
+	World       string            `json:"world,omitempty"`
+	Recruit     string            `json:"recruit,omitempty"`
+	Stats       PirateStats       `json:"stats"`
+	Talents     []string          `json:"talents,omitempty"`
+	Powers      []string          `json:"powers,omitempty"`
+	EpicTalents []string          `json:"epic_talents,omitempty"`
+	Promotions  []string          `json:"promotions,omitempty"`
+	Infobox     map[string]string `json:"infobox"`
+}
+
+// Ship represents a Pirate101 ship decoded from a ship infobox.
+type Ship struct {
+	Name    string            `json:"name"`
+	Type    string            `json:"type"`
+	Level   int               `json:"level"`
+	Health  int               `json:"health"`
+	Armor   int               `json:"armor,omitempty"`
+	Speed   int               `json:"speed,omitempty"`
+	Turning int               `json:"turning,omitempty"`
+	Cannons int               `json:"cannons,omitempty"`
+	Sources []string          `json:"sources,omitempty"`
+	Infobox map[string]string `json:"infobox"`
+}
+
+// PirateItem represents a piece of Pirate101 gear decoded from an item infobox.
+type PirateItem struct {
+	Name        string            `json:"name"`
+	Slot        string            `json:"slot"`
+	Level       int               `json:"level"`
+	Classes     []string          `json:"classes,omitempty"`
+	Stats       PirateStats       `json:"stats"`
+	Talents     []string          `json:"talents,omitempty"`
+	Auctionable bool              `json:"auctionable"`
+	Tradeable   bool              `json:"tradeable"`
+	Sources     []string          `json:"sources,omitempty"`
+	Infobox     map[string]string `json:"infobox"`
+}
+
+// companionInfoboxNames lists the template names used for companion pages.
+var companionInfoboxNames = []string{"CompanionInfobox", "Companion Infobox", "Infobox Companion"}
+
+// shipInfoboxNames lists the template names used for ship pages.
+var shipInfoboxNames = []string{"ShipInfobox", "Ship Infobox", "Infobox Ship"}
+
+// pirateItemInfoboxNames lists the template names used for Pirate101 item pages.
+var pirateItemInfoboxNames = []string{"ItemInfobox", "Item Infobox", "Infobox Item", "GearInfobox", "WeaponInfobox"}
+
+// PirateExportEntities lists the typed Pirate101 entities, for an Exporter on a Pirate101 service.
+var PirateExportEntities = []ExportEntity{
+	{Name: "companions", Category: "Companions", Decode: func(s *WikiService, title string) (interface{}, error) { return s.GetCompanion(title) }},
+	{Name: "ships", Category: "Ships", Decode: func(s *WikiService, title string) (interface{}, error) { return s.GetShip(title) }},
+	{Name: "items", Category: "Items", Decode: func(s *WikiService, title string) (interface{}, error) { return s.GetPirateItem(title) }},
+}
+
+// GetCompanion retrieves a companion page and decodes its infobox.
+func (s *WikiService) GetCompanion(title string) (*Companion, error) {
+	t, wiki, err := s.findInfobox(title, "companion")
+	if err != nil {
+		return nil, err
+	}
+	return DecodeCompanion(wiki.Parse.Title, t), nil
+}
+
+// GetShip retrieves a ship page and decodes its infobox.
+func (s *WikiService) GetShip(title string) (*Ship, error) {
+	t, wiki, err := s.findInfobox(title, "ship")
+	if err != nil {
+		return nil, err
+	}
+	return DecodeShip(wiki.Parse.Title, t), nil
+}
+
+// GetPirateItem retrieves a Pirate101 item page and decodes its infobox.
+func (s *WikiService) GetPirateItem(title string) (*PirateItem, error) {
+	t, wiki, err := s.findInfobox(title, "pirateitem")
+	if err != nil {
+		return nil, err
+	}
+	return DecodePirateItem(wiki.Parse.Title, t), nil
+}
+
+// DecodeCompanion builds a Companion from a companion infobox template.
+func DecodeCompanion(title string, t Template) *Companion {
+	f := newInfoboxFields(t)
+
+	companion := &Companion{
+		Name:        f.text("name"),
+		Class:       parsePirateClass(f.text("class", "type")),
+		Species:     f.text("species", "race"),
+		World:       f.text("world"),
+		Recruit:     f.text("recruit", "recruited", "recruitedfrom", "location", "quest", "source"),
+		Stats:       pirateStats(f),
+		Talents:     f.list("talents", "talent", "abilities"),
+		Powers:      f.list("powers", "power"),
+		EpicTalents: f.list("epics", "epictalents", "epic"),
+		Promotions:  f.list("promotions", "promotion", "ranks"),
+		Infobox:     t.Map(),
+	}
+	if companion.Name == "" {
+		companion.Name = stripNamespace(title)
+	}
+
+	// Talents may also be numbered parameters such as "talent1".
+	for n := 1; n <= 20; n++ {
+		if talent := f.text(fmt.Sprintf("talent%d", n)); talent != "" {
+			companion.Talents = append(companion.Talents, talent)
+		}
+	}
+
+	return companion
+}
+
+// DecodeShip builds a Ship from a ship infobox template.
+func DecodeShip(title string, t Template) *Ship {
+	f := newInfoboxFields(t)
+
+	ship := &Ship{
+		Name:    f.text("name"),
+		Type:    f.text("type", "class", "shiptype", "hull"),
+		Level:   f.int("level", "levelreq", "reqlevel", "requiredlevel"),
+		Health:  f.int("health", "hp", "maxhealth", "hullhealth"),
+		Armor:   f.int("armor", "armour"),
+		Speed:   f.int("speed"),
+		Turning: f.int("turning", "turn", "turnrate", "maneuver", "maneuverability"),
+		Cannons: f.int("cannons", "cannonslots", "guns", "broadsides"),
+		Infobox: t.Map(),
+	}
+	if ship.Name == "" {
+		ship.Name = stripNamespace(title)
+	}
+
+	for _, key := range []string{"source", "sources", "vendor", "soldby", "crafted", "quest", "reward", "dropsfrom", "droppedby"} {
+		ship.Sources = append(ship.Sources, f.list(key)...)
+	}
+
+	return ship
+}
+
+// DecodePirateItem builds a PirateItem from a Pirate101 item infobox template.
+func DecodePirateItem(title string, t Template) *PirateItem {
+	f := newInfoboxFields(t)
+
+	item := &PirateItem{
+		Name:    f.text("name"),
+		Slot:    f.text("type", "itemtype", "slot", "kind", "category"),
+		Level:   f.int("level", "levelreq", "reqlevel", "requiredlevel", "minlevel"),
+		Stats:   pirateStats(f),
+		Talents: f.list("talents", "talent", "powers", "grants"),
+		Infobox: t.Map(),
+	}
+	if item.Name == "" {
+		item.Name = stripNamespace(title)
+	}
+
+	for _, class := range f.list("class", "classes", "classreq", "classrestriction") {
+		item.Classes = append(item.Classes, parsePirateClass(class))
+	}
+
+	item.Auctionable = flag(f, []string{"auction", "auctionable", "auctionhouse"}, []string{"noauction"})
+	item.Tradeable = flag(f, []string{"trade", "tradeable", "tradable"}, []string{"notrade", "notradeable"})
+
+	for _, key := range []string{"source", "sources", "dropsfrom", "droppedby", "drops", "vendor", "soldby", "crafted", "quest", "reward"} {
+		item.Sources = append(item.Sources, f.list(key)...)
+	}
+
+	return item
+}
+
+// pirateStats reads the Pirate101 stats of an infobox.
+func pirateStats(f infoboxFields) PirateStats {
+	return PirateStats{
+		Strength: f.int("strength", "str"),
+		Agility:  f.int("agility", "agi"),
+		Will:     f.int("will", "willpower"),
+		Health:   f.int("health", "hp", "maxhealth"),
+		Armor:    f.int("armor", "armour"),
+		Accuracy: f.int("accuracy"),
+		Dodge:    f.int("dodge"),
+	}
+}
+
+// parsePirateClass returns the canonical Pirate101 class named in s, or s itself.
+func parsePirateClass(s string) string {
+	s = strings.TrimSpace(s)
+	for _, class := range PirateClasses {
+		if strings.EqualFold(s, class) || strings.EqualFold(s, class+"s") {
+			return class
+		}
+	}
+	return s
+}
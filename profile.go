@@ -0,0 +1,104 @@
+package wizlib
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedKind is returned by a typed getter whose entity kind is not in the service's profile,
+// such as GetItem on a Pirate101 service, whose items use a different decoder.
+var ErrUnsupportedKind = errors.New("entity kind not supported by this wiki")
+
+// WikiProfile describes a game wiki: where its API lives and which infobox templates its pages use.
+// Profiles are plain values, so a copy can be adjusted for a mirror or a wiki that renamed its templates.
+type WikiProfile struct {
+	Name     string `json:"name"`
+	Endpoint string `json:"endpoint"`
+	// LinkBase is the URL that page titles are appended to for links, used by NewProfileRenderer.
+	LinkBase string `json:"link_base"`
+	// Templates maps an entity kind, such as "item", to the infobox template names used for it.
+	// Kinds missing from the map are not on the wiki, and their getters fail with ErrUnsupportedKind.
+	Templates map[string][]string `json:"templates"`
+}
+
+// Wizard101Profile is the profile of the Wizard101 Central wiki, used when a service has no profile.
+var Wizard101Profile = WikiProfile{
+	Name:     "Wizard101",
+	Endpoint: apiURL,
+	LinkBase: "https://wiki.wizard101central.com/wiki/",
+	Templates: map[string][]string{
+		"item":     itemInfoboxNames,
+		"creature": creatureInfoboxNames,
+		"spell":    spellInfoboxNames,
+		"pet":      petInfoboxNames,
+		"quest":    questInfoboxNames,
+		"recipe":   recipeInfoboxNames,
+		"location": locationInfoboxNames,
+	},
+}
+
+// Pirate101Profile is the profile of the Pirate101 Central wiki.
+var Pirate101Profile = WikiProfile{
+	Name:     "Pirate101",
+	Endpoint: "https://www.pirate101central.com/wiki/api.php",
+	LinkBase: "https://www.pirate101central.com/wiki/",
+	Templates: map[string][]string{
+		"companion":  companionInfoboxNames,
+		"ship":       shipInfoboxNames,
+		"pirateitem": pirateItemInfoboxNames,
+		"location":   locationInfoboxNames,
+		"quest":      questInfoboxNames,
+	},
+}
+
+// NewProfileWikiService creates a WikiService for the wiki described by profile, with its own cache.
+// Services for different wikis must not share a cache, since their page titles overlap.
+func NewProfileWikiService(client *APIClient, profile WikiProfile) *WikiService {
+	s := NewWikiService(client)
+	s.Profile = &profile
+	return s
+}
+
+// NewProfileRenderer creates a Renderer linking to the wiki described by profile.
+func NewProfileRenderer(profile WikiProfile) *Renderer {
+	r := NewRenderer()
+	r.LinkBase = profile.LinkBase
+	return r
+}
+
+// profile returns the service's profile, or the Wizard101 profile when it has none.
+func (s *WikiService) profile() *WikiProfile {
+	if s.Profile != nil {
+		return s.Profile
+	}
+	return &Wizard101Profile
+}
+
+// infoboxNames returns the template names of an entity kind for the service's wiki.
+func (s *WikiService) infoboxNames(kind string) ([]string, error) {
+	profile := s.profile()
+	names := profile.Templates[kind]
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%w: %s on %s", ErrUnsupportedKind, kind, profile.Name)
+	}
+	return names, nil
+}
+
+// findInfobox retrieves a page and finds the infobox of an entity kind, using the service's profile.
+// Unsupported kinds fail before the page is fetched.
+func (s *WikiService) findInfobox(title, kind string) (Template, WikiResponse, error) {
+	names, err := s.infoboxNames(kind)
+	if err != nil {
+		return Template{}, WikiResponse{}, err
+	}
+	wiki, err := s.GetWikiText(title)
+	if err != nil {
+		return Template{}, WikiResponse{}, err
+	}
+
+	t, ok := FindTemplate(wiki.Parse.Content, names...)
+	if !ok {
+		return Template{}, WikiResponse{}, fmt.Errorf("%s: %w", title, ErrNoInfobox)
+	}
+	return t, wiki, nil
+}
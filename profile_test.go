@@ -0,0 +1,38 @@
+package wizlib
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProfileEndpointAndKinds(t *testing.T) {
+	w, s := newFakeWiki(t)
+	profile := Pirate101Profile
+	profile.Endpoint, s.Endpoint = s.Endpoint, ""
+	s.Profile = &profile
+
+	item, err := s.GetPirateItem("Fire Hat")
+	if err != nil {
+		t.Fatalf("GetPirateItem: %v", err)
+	}
+	if item.Level != 10 {
+		t.Errorf("GetPirateItem level: got %d, want 10", item.Level)
+	}
+
+	s.Invalidate("Fire Hat")
+	w.pages = map[string]*fakePage{}
+	if _, err := s.GetItem("Fire Hat"); !errors.Is(err, ErrUnsupportedKind) {
+		t.Errorf("GetItem on Pirate101: got %v, want ErrUnsupportedKind", err)
+	}
+	if _, err := s.GetCreature("Fire Hat"); !errors.Is(err, ErrUnsupportedKind) {
+		t.Errorf("GetCreature on Pirate101: got %v, want ErrUnsupportedKind", err)
+	}
+}
+
+func TestNewProfileRenderer(t *testing.T) {
+	r := NewProfileRenderer(Pirate101Profile)
+	want := "[Fire Hat](<https://www.pirate101central.com/wiki/Fire_Hat>)"
+	if got := r.Markdown("[[Fire Hat]]"); got != want {
+		t.Errorf("Markdown: got %q, want %q", got, want)
+	}
+}
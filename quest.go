@@ -0,0 +1,393 @@
+package wizlib
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Quest represents a quest decoded from a quest infobox.
+type Quest struct {
+	Name     string            `json:"name"`
+	World    string            `json:"world,omitempty"`
+	Area     string            `json:"area,omitempty"`
+	Giver    string            `json:"giver,omitempty"`
+	Level    int               `json:"level,omitempty"`
+	Type     string            `json:"type,omitempty"`
+	Previous []string          `json:"previous,omitempty"`
+	Next     []string          `json:"next,omitempty"`
+	Infobox  map[string]string `json:"infobox"`
+}
+
+// questInfoboxNames lists the template names used for quest pages.
+var questInfoboxNames = []string{"QuestInfobox", "Quest Infobox", "Infobox Quest"}
+
+// GetQuest retrieves a quest page and decodes its infobox.
+func (s *WikiService) GetQuest(title string) (*Quest, error) {
+	t, wiki, err := s.findInfobox(title, "quest")
+	if err != nil {
+		return nil, err
+	}
+	return DecodeQuest(wiki.Parse.Title, t), nil
+}
+
+// DecodeQuest builds a Quest from a quest infobox template.
+func DecodeQuest(title string, t Template) *Quest {
+	f := newInfoboxFields(t)
+
+	quest := &Quest{
+		Name:     f.text("name"),
+		World:    f.text("world"),
+		Area:     f.text("area", "location", "zone"),
+		Giver:    f.text("giver", "questgiver", "startnpc", "from"),
+		Level:    f.int("level", "minlevel"),
+		Type:     f.text("type", "questtype", "goal"),
+		Previous: linkTargets(f.raw("previous", "prev", "prevquest", "previousquest", "prior", "priorquest", "before", "requires")),
+		Next:     linkTargets(f.raw("next", "nextquest", "after", "followup", "unlocks")),
+		Infobox:  t.Map(),
+	}
+	if quest.Name == "" {
+		quest.Name = stripNamespace(title)
+	}
+
+	return quest
+}
+
+// ErrCrawlLimit is returned by QuestGraph.Crawl when MaxDepth or MaxPages stopped it before every
+// linked quest was fetched. The quests fetched so far are kept in the graph.
+var ErrCrawlLimit = errors.New("quest crawl stopped at a limit")
+
+// QuestGraph links quests to the quests that follow them.
+type QuestGraph struct {
+	Quests map[string]*Quest
+	// MaxDepth limits how many previous or next links Crawl follows from a seed; zero means no limit.
+	MaxDepth int
+	// MaxPages limits how many quest pages a single Crawl fetches; zero means no limit.
+	MaxPages int
+
+	next map[string]map[string]bool
+	prev map[string]map[string]bool
+}
+
+// NewQuestGraph creates a new, empty instance of QuestGraph.
+func NewQuestGraph() *QuestGraph {
+	return &QuestGraph{
+		Quests: make(map[string]*Quest),
+		next:   make(map[string]map[string]bool),
+		prev:   make(map[string]map[string]bool),
+	}
+}
+
+// AddQuest adds a quest and the links from its infobox to the graph.
+// Re-adding a quest replaces the links of its previous version.
+func (g *QuestGraph) AddQuest(title string, quest *Quest) {
+	title = normalizeTitle(title)
+	_, replaced := g.Quests[title]
+	g.Quests[title] = quest
+	if replaced {
+		// Another quest may assert the same link, so rebuild the links rather than removing them.
+		g.relink()
+		return
+	}
+	g.linkQuest(title, quest)
+}
+
+// relink rebuilds every link from the quests in the graph.
+func (g *QuestGraph) relink() {
+	g.next = make(map[string]map[string]bool)
+	g.prev = make(map[string]map[string]bool)
+	for title, quest := range g.Quests {
+		g.linkQuest(title, quest)
+	}
+}
+
+// linkQuest adds the links from a quest's infobox.
+func (g *QuestGraph) linkQuest(title string, quest *Quest) {
+	for _, prev := range quest.Previous {
+		g.link(prev, title)
+	}
+	for _, next := range quest.Next {
+		g.link(title, next)
+	}
+}
+
+// Crawl fetches the seed quests and follows previous and next links until no new quests are found,
+// MaxDepth links from a seed, or MaxPages quests were fetched. If a limit left linked quests unfetched,
+// Crawl returns ErrCrawlLimit. Linked pages that are missing or are not quests are left out of the graph.
+func (g *QuestGraph) Crawl(s *WikiService, seeds ...string) error {
+	type queued struct {
+		title string
+		depth int
+	}
+	var queue []queued
+	for _, seed := range seeds {
+		queue = append(queue, queued{title: seed})
+	}
+	seen := make(map[string]bool)
+	// skipped holds quests left out by a limit; they may still be fetched through another link.
+	skipped := make(map[string]bool)
+
+	for fetched := 0; len(queue) > 0; {
+		item := queue[0]
+		queue = queue[1:]
+		title := normalizeTitle(item.title)
+		if seen[title] {
+			continue
+		}
+		if g.MaxPages > 0 && fetched >= g.MaxPages {
+			skipped[title] = true
+			continue
+		}
+		seen[title] = true
+		fetched++
+
+		quest, err := s.GetQuest(title)
+		if errors.Is(err, ErrPageNotFound) || errors.Is(err, ErrNoInfobox) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		g.AddQuest(title, quest)
+		for _, linked := range append(append([]string(nil), quest.Previous...), quest.Next...) {
+			if g.MaxDepth > 0 && item.depth >= g.MaxDepth {
+				skipped[normalizeTitle(linked)] = true
+				continue
+			}
+			queue = append(queue, queued{title: linked, depth: item.depth + 1})
+		}
+	}
+
+	for title := range skipped {
+		if !seen[title] {
+			return ErrCrawlLimit
+		}
+	}
+	return nil
+}
+
+// CrawlCategory adds every quest in a category, such as "Wizard City Quests", and follows their links.
+func (g *QuestGraph) CrawlCategory(s *WikiService, category string) error {
+	titles, err := s.CategoryMembers(category)
+	if err != nil {
+		return err
+	}
+	return g.Crawl(s, titles...)
+}
+
+// Between returns the quests on any path from a to b, including both, in the order they are played.
+func (g *QuestGraph) Between(a, b string) ([]string, error) {
+	a, b = normalizeTitle(a), normalizeTitle(b)
+
+	after := g.reachable(a, g.next)
+	before := g.reachable(b, g.prev)
+	if !after[b] {
+		return nil, fmt.Errorf("%s does not lead to %s", a, b)
+	}
+
+	between := make(map[string]bool)
+	for title := range after {
+		if before[title] {
+			between[title] = true
+		}
+	}
+
+	return g.order(between), nil
+}
+
+// Prerequisites returns every quest that must be completed before the given quest, in the order they are played.
+func (g *QuestGraph) Prerequisites(quest string) []string {
+	ancestors := g.reachable(normalizeTitle(quest), g.prev)
+	delete(ancestors, normalizeTitle(quest))
+	return g.order(ancestors)
+}
+
+// Remaining returns the quests after the given one that are in the same world, in the order they are played.
+// When the quest has no world, or was not fetched, the quests after it in every world are returned.
+func (g *QuestGraph) Remaining(quest string) []string {
+	quest = normalizeTitle(quest)
+	world := ""
+	if q, ok := g.Quests[quest]; ok {
+		world = q.World
+	}
+
+	remaining := make(map[string]bool)
+	for title := range g.reachable(quest, g.next) {
+		q, ok := g.Quests[title]
+		if !ok || title == quest {
+			continue
+		}
+		if world == "" || strings.EqualFold(q.World, world) {
+			remaining[title] = true
+		}
+	}
+
+	return g.order(remaining)
+}
+
+// WriteDOT writes the graph in Graphviz DOT format, grouping quests by world.
+func (g *QuestGraph) WriteDOT(w io.Writer) error {
+	worlds := make(map[string][]string)
+	for title := range g.nodes() {
+		world := ""
+		if q, ok := g.Quests[title]; ok {
+			world = q.World
+		}
+		worlds[world] = append(worlds[world], title)
+	}
+
+	names := make([]string, 0, len(worlds))
+	for world := range worlds {
+		names = append(names, world)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString("digraph quests {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	for i, world := range names {
+		titles := worlds[world]
+		sort.Strings(titles)
+
+		indent := "\t"
+		if world != "" {
+			fmt.Fprintf(&sb, "\tsubgraph cluster_%d {\n\t\tlabel=%s;\n", i, dotQuote(world))
+			indent = "\t\t"
+		}
+		for _, title := range titles {
+			label := title
+			if q, ok := g.Quests[title]; ok {
+				label = q.Name
+			}
+			fmt.Fprintf(&sb, "%s%s [label=%s];\n", indent, dotQuote(title), dotQuote(label))
+		}
+		if world != "" {
+			sb.WriteString("\t}\n")
+		}
+	}
+
+	for _, from := range sortedKeys(g.next) {
+		for _, to := range sortedKeys(g.next[from]) {
+			fmt.Fprintf(&sb, "\t%s -> %s;\n", dotQuote(from), dotQuote(to))
+		}
+	}
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// link records that quest to follows quest from.
+func (g *QuestGraph) link(from, to string) {
+	from, to = normalizeTitle(from), normalizeTitle(to)
+	if g.next[from] == nil {
+		g.next[from] = make(map[string]bool)
+	}
+	if g.prev[to] == nil {
+		g.prev[to] = make(map[string]bool)
+	}
+	g.next[from][to] = true
+	g.prev[to][from] = true
+}
+
+// nodes returns every quest in the graph, including linked quests that were not fetched.
+func (g *QuestGraph) nodes() map[string]bool {
+	nodes := make(map[string]bool)
+	for title := range g.Quests {
+		nodes[title] = true
+	}
+	for from, tos := range g.next {
+		nodes[from] = true
+		for to := range tos {
+			nodes[to] = true
+		}
+	}
+	return nodes
+}
+
+// reachable returns start and every quest reachable from it along edges.
+func (g *QuestGraph) reachable(start string, edges map[string]map[string]bool) map[string]bool {
+	seen := map[string]bool{start: true}
+	stack := []string{start}
+	for len(stack) > 0 {
+		title := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for next := range edges[title] {
+			if !seen[next] {
+				seen[next] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+	return seen
+}
+
+// order sorts a set of quests so that every quest comes after its predecessors.
+// Quests caught in a cycle, which only happens with broken wiki links, are appended alphabetically.
+func (g *QuestGraph) order(set map[string]bool) []string {
+	indegree := make(map[string]int, len(set))
+	for title := range set {
+		for prev := range g.prev[title] {
+			if set[prev] {
+				indegree[title]++
+			}
+		}
+	}
+
+	var ready []string
+	for title := range set {
+		if indegree[title] == 0 {
+			ready = append(ready, title)
+		}
+	}
+
+	var ordered []string
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		title := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, title)
+		for next := range g.next[title] {
+			if !set[next] {
+				continue
+			}
+			if indegree[next]--; indegree[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+	}
+
+	if len(ordered) < len(set) {
+		done := make(map[string]bool, len(ordered))
+		for _, title := range ordered {
+			done[title] = true
+		}
+		var rest []string
+		for title := range set {
+			if !done[title] {
+				rest = append(rest, title)
+			}
+		}
+		sort.Strings(rest)
+		ordered = append(ordered, rest...)
+	}
+
+	return ordered
+}
+
+// sortedKeys returns the keys of a set in alphabetical order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// dotQuote quotes a string for use as a DOT identifier or label.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,47 @@
+package wizlib
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestQuestGraphReAddReplacesLinks(t *testing.T) {
+	g := NewQuestGraph()
+	g.AddQuest("B", &Quest{})
+	g.AddQuest("D", &Quest{})
+	g.AddQuest("A", &Quest{Next: []string{"B"}})
+	g.AddQuest("C", &Quest{Previous: []string{"A"}})
+	g.AddQuest("A", &Quest{Next: []string{"D"}})
+
+	want := []string{"C", "D"}
+	if got := g.Remaining("A"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Remaining(A) = %q, want %q", got, want)
+	}
+}
+
+func TestQuestGraphCrawlLimit(t *testing.T) {
+	w, s := newFakeWiki(t)
+	w.pages = map[string]*fakePage{
+		"Quest 1": {PageID: 1, RevID: 1, Text: "{{QuestInfobox\n|next = [[Quest 2]]\n}}"},
+		"Quest 2": {PageID: 2, RevID: 2, Text: "{{QuestInfobox\n|previous = [[Quest 1]]\n|next = [[Quest 3]]\n}}"},
+		"Quest 3": {PageID: 3, RevID: 3, Text: "{{QuestInfobox\n|previous = [[Quest 2]]\n}}"},
+	}
+
+	g := NewQuestGraph()
+	if err := g.Crawl(s, "Quest 1"); err != nil {
+		t.Fatalf("Crawl without limits: %v", err)
+	}
+	if len(g.Quests) != 3 {
+		t.Errorf("Crawl without limits found %d quests, want 3", len(g.Quests))
+	}
+
+	g = NewQuestGraph()
+	g.MaxDepth = 1
+	if err := g.Crawl(s, "Quest 1"); !errors.Is(err, ErrCrawlLimit) {
+		t.Errorf("Crawl with MaxDepth 1: got %v, want ErrCrawlLimit", err)
+	}
+	if len(g.Quests) != 2 {
+		t.Errorf("Crawl with MaxDepth 1 found %d quests, want 2", len(g.Quests))
+	}
+}
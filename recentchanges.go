@@ -0,0 +1,370 @@
+package wizlib
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChangeType identifies the kind of change reported by the wiki.
+type ChangeType string
+
+const (
+	ChangeEdit   ChangeType = "edit"
+	ChangeNew    ChangeType = "new"
+	ChangeDelete ChangeType = "delete"
+	ChangeMove   ChangeType = "move"
+)
+
+// ChangeEvent represents a single entry of the wiki's recent changes feed.
+type ChangeEvent struct {
+	Type      ChangeType `json:"type"`
+	Title     string     `json:"title"`
+	NewTitle  string     `json:"new_title,omitempty"`
+	Namespace int        `json:"namespace"`
+	PageID    int64      `json:"pageid"`
+	RevID     int64      `json:"revid"`
+	OldRevID  int64      `json:"old_revid"`
+	User      string     `json:"user"`
+	Comment   string     `json:"comment"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+type recentChangesResponse struct {
+	Continue map[string]string `json:"continue"`
+	Query    struct {
+		RecentChanges []struct {
+			Type      string    `json:"type"`
+			Namespace int       `json:"ns"`
+			Title     string    `json:"title"`
+			PageID    int64     `json:"pageid"`
+			RevID     int64     `json:"revid"`
+			OldRevID  int64     `json:"old_revid"`
+			RCID      int64     `json:"rcid"`
+			User      string    `json:"user"`
+			Comment   string    `json:"comment"`
+			Timestamp time.Time `json:"timestamp"`
+			LogType   string    `json:"logtype"`
+			LogAction string    `json:"logaction"`
+			LogParams struct {
+				TargetTitle string `json:"target_title"`
+			} `json:"logparams"`
+		} `json:"recentchanges"`
+	} `json:"query"`
+	Error *APIError `json:"error"`
+}
+
+// watcherState is the continuation point persisted between polls.
+type watcherState struct {
+	Timestamp time.Time `json:"timestamp"`
+	LastRCID  int64     `json:"last_rcid"`
+}
+
+// RecentChangesWatcher polls the wiki's recent changes feed and reports page changes.
+// Cached WikiService entries for changed pages are invalidated automatically.
+type RecentChangesWatcher struct {
+	Service    *WikiService
+	Namespaces []int
+	Interval   time.Duration
+	StatePath  string
+
+	mu    sync.Mutex
+	state watcherState
+}
+
+// NewRecentChangesWatcher creates a new instance of RecentChangesWatcher.
+// The continuation timestamp is loaded from and saved to statePath; an empty path disables persistence.
+func NewRecentChangesWatcher(service *WikiService, statePath string) (*RecentChangesWatcher, error) {
+	w := &RecentChangesWatcher{
+		Service:   service,
+		Interval:  time.Minute,
+		StatePath: statePath,
+	}
+
+	if statePath == "" {
+		return w, nil
+	}
+
+	data, err := os.ReadFile(statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return w, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &w.state); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Since returns the timestamp of the last change seen by the watcher.
+func (w *RecentChangesWatcher) Since() time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.state.Timestamp
+}
+
+// Poll fetches all changes since the last poll and advances the continuation point.
+// The first poll of a watcher without saved state only records the server's current position in the feed.
+func (w *RecentChangesWatcher) Poll() ([]ChangeEvent, error) {
+	events, next, err := w.fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	w.invalidate(events)
+
+	return events, w.commit(next)
+}
+
+// Watch polls the wiki every Interval, or every minute if Interval is not positive, and delivers
+// changes on the returned channel until ctx is done.
+// Poll errors are reported on the error channel; they are dropped if nobody is receiving.
+func (w *RecentChangesWatcher) Watch(ctx context.Context) (<-chan ChangeEvent, <-chan error) {
+	events := make(chan ChangeEvent)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(events)
+		defer close(errs)
+
+		interval := w.Interval
+		if interval <= 0 {
+			interval = time.Minute
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := w.deliver(ctx, events); err != nil {
+				select {
+				case errs <- err:
+				default:
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return events, errs
+}
+
+// deliver runs a single poll and sends its events, committing the new state once all were sent.
+func (w *RecentChangesWatcher) deliver(ctx context.Context, out chan<- ChangeEvent) error {
+	events, next, err := w.fetch()
+	if err != nil {
+		return err
+	}
+
+	w.invalidate(events)
+
+	for _, event := range events {
+		select {
+		case out <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return w.commit(next)
+}
+
+// fetch retrieves every change after the current state, following API continuation.
+func (w *RecentChangesWatcher) fetch() ([]ChangeEvent, watcherState, error) {
+	w.mu.Lock()
+	state := w.state
+	w.mu.Unlock()
+
+	if state.Timestamp.IsZero() {
+		next, err := w.seed()
+		return nil, next, err
+	}
+
+	params := url.Values{}
+	params.Set("action", "query")
+	params.Set("list", "recentchanges")
+	params.Set("rcdir", "newer")
+	params.Set("rcstart", state.Timestamp.UTC().Format(time.RFC3339))
+	params.Set("rcprop", "title|ids|timestamp|user|comment|loginfo")
+	params.Set("rctype", "edit|new|log")
+	params.Set("rclimit", "500")
+	if len(w.Namespaces) > 0 {
+		namespaces := make([]string, len(w.Namespaces))
+		for i, ns := range w.Namespaces {
+			namespaces[i] = strconv.Itoa(ns)
+		}
+		params.Set("rcnamespace", strings.Join(namespaces, "|"))
+	}
+
+	var events []ChangeEvent
+	next := state
+	for {
+		body, err := w.Service.query(params)
+		if err != nil {
+			return nil, state, err
+		}
+
+		var response recentChangesResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, state, err
+		}
+		if response.Error != nil {
+			return nil, state, response.Error
+		}
+
+		for _, rc := range response.Query.RecentChanges {
+			// rcstart is inclusive, so changes sharing the last timestamp are seen twice.
+			if rc.RCID <= state.LastRCID {
+				continue
+			}
+			if rc.RCID > next.LastRCID {
+				next = watcherState{Timestamp: rc.Timestamp, LastRCID: rc.RCID}
+			}
+
+			event := ChangeEvent{
+				Title:     rc.Title,
+				Namespace: rc.Namespace,
+				PageID:    rc.PageID,
+				RevID:     rc.RevID,
+				OldRevID:  rc.OldRevID,
+				User:      rc.User,
+				Comment:   rc.Comment,
+				Timestamp: rc.Timestamp,
+			}
+
+			switch {
+			case rc.Type == "edit":
+				event.Type = ChangeEdit
+			case rc.Type == "new":
+				event.Type = ChangeNew
+			case rc.Type == "log" && rc.LogType == "delete" && rc.LogAction == "delete":
+				event.Type = ChangeDelete
+			case rc.Type == "log" && rc.LogType == "move":
+				event.Type = ChangeMove
+				event.NewTitle = rc.LogParams.TargetTitle
+			default:
+				continue
+			}
+
+			events = append(events, event)
+		}
+
+		cont, ok := response.Continue["rccontinue"]
+		if !ok {
+			break
+		}
+		params.Set("rccontinue", cont)
+	}
+
+	return events, next, nil
+}
+
+// seed returns the continuation point of a watcher without saved state: the newest change in the
+// feed, or the server's clock if the feed is empty. The local clock is not used, since any skew
+// against the server would lose or repeat changes.
+func (w *RecentChangesWatcher) seed() (watcherState, error) {
+	params := url.Values{}
+	params.Set("action", "query")
+	params.Set("list", "recentchanges")
+	params.Set("rcprop", "ids|timestamp")
+	params.Set("rclimit", "1")
+	params.Set("curtimestamp", "1")
+
+	body, err := w.Service.query(params)
+	if err != nil {
+		return watcherState{}, err
+	}
+
+	var response struct {
+		CurTimestamp time.Time `json:"curtimestamp"`
+		Query        struct {
+			RecentChanges []struct {
+				RCID      int64     `json:"rcid"`
+				Timestamp time.Time `json:"timestamp"`
+			} `json:"recentchanges"`
+		} `json:"query"`
+		Error *APIError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return watcherState{}, err
+	}
+	if response.Error != nil {
+		return watcherState{}, response.Error
+	}
+
+	if rc := response.Query.RecentChanges; len(rc) > 0 {
+		return watcherState{Timestamp: rc[0].Timestamp, LastRCID: rc[0].RCID}, nil
+	}
+	if response.CurTimestamp.IsZero() {
+		return watcherState{}, errors.New("mediawiki: no server timestamp returned")
+	}
+	return watcherState{Timestamp: response.CurTimestamp}, nil
+}
+
+// invalidate drops cached pages affected by the given changes.
+func (w *RecentChangesWatcher) invalidate(events []ChangeEvent) {
+	for _, event := range events {
+		w.Service.Invalidate(event.Title)
+		if event.NewTitle != "" {
+			w.Service.Invalidate(event.NewTitle)
+		}
+	}
+}
+
+// commit stores the new continuation point and persists it to StatePath.
+func (w *RecentChangesWatcher) commit(next watcherState) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.state = next
+	if w.StatePath == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(w.state)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(w.StatePath, data)
+}
+
+// writeFileAtomic writes data to a temporary file, syncs it and renames it over path.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
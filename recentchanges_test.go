@@ -0,0 +1,26 @@
+package wizlib
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPollReturnsAPIError(t *testing.T) {
+	_, s := newFakeWiki(t)
+	w, err := NewRecentChangesWatcher(s, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	w.state = watcherState{Timestamp: since}
+
+	// The fake wiki answers list=recentchanges with an error.
+	var apiErr *APIError
+	if _, err := w.Poll(); !errors.As(err, &apiErr) {
+		t.Fatalf("Poll: got %v, want an APIError", err)
+	}
+	if !w.Since().Equal(since) {
+		t.Errorf("Since after a failed poll = %v, want %v", w.Since(), since)
+	}
+}
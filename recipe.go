@@ -0,0 +1,237 @@
+package wizlib
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ErrRecipeCycle is returned when recipes depend on each other in a loop.
+var ErrRecipeCycle = errors.New("recipe cycle")
+
+// Ingredient represents an item and the quantity needed of it.
+type Ingredient struct {
+	Item     string `json:"item"`
+	Quantity int    `json:"quantity"`
+}
+
+// Recipe represents a crafting recipe decoded from a recipe infobox.
+type Recipe struct {
+	Name        string            `json:"name"`
+	Creates     string            `json:"creates"`
+	Yield       int               `json:"yield"`
+	Station     string            `json:"station,omitempty"`
+	Slots       int               `json:"slots,omitempty"`
+	Ingredients []Ingredient      `json:"ingredients"`
+	Vendor      string            `json:"vendor,omitempty"`
+	Cooldown    string            `json:"cooldown,omitempty"`
+	Infobox     map[string]string `json:"infobox"`
+}
+
+// recipeInfoboxNames lists the template names used for recipe pages.
+var recipeInfoboxNames = []string{"RecipeInfobox", "Recipe Infobox", "Infobox Recipe"}
+
+// GetRecipe retrieves a recipe page and decodes its infobox.
+func (s *WikiService) GetRecipe(title string) (*Recipe, error) {
+	t, wiki, err := s.findInfobox(title, "recipe")
+	if err != nil {
+		return nil, err
+	}
+	return DecodeRecipe(wiki.Parse.Title, t), nil
+}
+
+// DecodeRecipe builds a Recipe from a recipe infobox template.
+func DecodeRecipe(title string, t Template) *Recipe {
+	f := newInfoboxFields(t)
+
+	recipe := &Recipe{
+		Name:     f.text("name"),
+		Yield:    f.int("yield", "quantity", "amount", "makes"),
+		Station:  f.text("station", "craftingstation", "equipment"),
+		Slots:    f.int("slots", "craftingslots", "slot"),
+		Vendor:   f.text("vendor", "soldby", "vendors"),
+		Cooldown: f.text("cooldown", "time", "craftingtime"),
+		Infobox:  t.Map(),
+	}
+	if recipe.Name == "" {
+		recipe.Name = stripNamespace(title)
+	}
+	if recipe.Yield <= 0 {
+		recipe.Yield = 1
+	}
+
+	recipe.Creates = firstLinkOrText(f.raw("creates", "result", "item", "makesitem", "product"))
+	if recipe.Creates == "" {
+		recipe.Creates = stripNamespace(title)
+	}
+
+	// Ingredients are numbered parameter pairs, or a single list of "5 [[Reagent:Ash]]" lines.
+	for n := 1; n <= 12; n++ {
+		item := firstLinkOrText(f.raw(fmt.Sprintf("ingredient%d", n), fmt.Sprintf("reagent%d", n), fmt.Sprintf("component%d", n)))
+		if item == "" {
+			continue
+		}
+		quantity := f.int(fmt.Sprintf("quantity%d", n), fmt.Sprintf("amount%d", n), fmt.Sprintf("qty%d", n))
+		if quantity <= 0 {
+			quantity = 1
+		}
+		recipe.Ingredients = append(recipe.Ingredients, Ingredient{Item: item, Quantity: quantity})
+	}
+	for _, line := range strings.Split(breakRegex.ReplaceAllString(f.raw("ingredients", "reagents", "components"), "\n"), "\n") {
+		if ingredient, ok := parseIngredient(line); ok {
+			recipe.Ingredients = append(recipe.Ingredients, ingredient)
+		}
+	}
+
+	return recipe
+}
+
+var ingredientRegex = regexp.MustCompile(`^\s*[*#]?\s*(?:(\d+)\s*[x×]?\s+)?(.+?)(?:\s*[x×]\s*(\d+))?\s*$`)
+
+// parseIngredient parses lines such as "5 [[Reagent:Ash]]" or "[[Reagent:Ash]] x5".
+func parseIngredient(line string) (Ingredient, bool) {
+	m := ingredientRegex.FindStringSubmatch(line)
+	if m == nil {
+		return Ingredient{}, false
+	}
+
+	item := firstLinkOrText(m[2])
+	if item == "" {
+		return Ingredient{}, false
+	}
+
+	quantity := 1
+	if n, ok := parseInt(m[1] + m[3]); ok && n > 0 {
+		quantity = n
+	}
+
+	return Ingredient{Item: item, Quantity: quantity}, true
+}
+
+// firstLinkOrText returns the first page linked from value, or its plain text.
+func firstLinkOrText(value string) string {
+	if targets := linkTargets(value); len(targets) > 0 {
+		return targets[0]
+	}
+	return plainValue(value)
+}
+
+// CraftStep represents crafting a recipe a number of times.
+type CraftStep struct {
+	Recipe string `json:"recipe"`
+	Item   string `json:"item"`
+	Times  int    `json:"times"`
+}
+
+// CraftingPlan is the result of expanding a recipe down to raw reagents.
+type CraftingPlan struct {
+	Item         string       `json:"item"`
+	Quantity     int          `json:"quantity"`
+	ShoppingList []Ingredient `json:"shopping_list"`
+	Steps        []CraftStep  `json:"steps"`
+}
+
+// RecipeResolver expands recipes recursively through a WikiService, so every lookup is cached
+// and invalidated with the service's cache.
+// It is safe for concurrent use once its fields are set.
+type RecipeResolver struct {
+	Service *WikiService
+	// RecipeTitle returns the recipe page for an item; by default "Recipe:<item name>".
+	RecipeTitle func(item string) string
+}
+
+// NewRecipeResolver creates a new instance of RecipeResolver.
+func NewRecipeResolver(service *WikiService) *RecipeResolver {
+	return &RecipeResolver{
+		Service: service,
+		RecipeTitle: func(item string) string {
+			return "Recipe:" + stripNamespace(item)
+		},
+	}
+}
+
+// Resolve expands the recipe for quantity of item into total raw reagents and a crafting order.
+// Items without a recipe page are treated as raw reagents.
+func (r *RecipeResolver) Resolve(item string, quantity int) (*CraftingPlan, error) {
+	item = normalizeTitle(item)
+
+	// Collect the recipes reachable from item, dependencies before the recipes using them.
+	var order []string
+	recipes := make(map[string]*Recipe)
+	state := make(map[string]int)
+	var visit func(item string, path []string) error
+	visit = func(item string, path []string) error {
+		switch state[item] {
+		case 1:
+			cycle := append(append([]string(nil), path...), item)
+			return fmt.Errorf("%w: %s", ErrRecipeCycle, strings.Join(cycle, " -> "))
+		case 2:
+			return nil
+		}
+
+		recipe, err := r.recipe(item)
+		if err != nil {
+			return err
+		}
+		if recipe == nil {
+			state[item] = 2
+			return nil
+		}
+
+		recipes[item] = recipe
+		state[item] = 1
+		// Each branch gets its own copy of the path, so siblings cannot overwrite it.
+		next := append(append(make([]string, 0, len(path)+1), path...), item)
+		for _, ingredient := range recipe.Ingredients {
+			if err := visit(normalizeTitle(ingredient.Item), next); err != nil {
+				return err
+			}
+		}
+		state[item] = 2
+		order = append(order, item)
+
+		return nil
+	}
+	if err := visit(item, nil); err != nil {
+		return nil, err
+	}
+
+	// Walk from the target down to the reagents, accumulating how much of each item is needed.
+	need := map[string]int{item: quantity}
+	plan := &CraftingPlan{Item: item, Quantity: quantity}
+	crafts := make(map[string]int)
+	for i := len(order) - 1; i >= 0; i-- {
+		recipe := recipes[order[i]]
+		times := (need[order[i]] + recipe.Yield - 1) / recipe.Yield
+		crafts[order[i]] = times
+		for _, ingredient := range recipe.Ingredients {
+			need[normalizeTitle(ingredient.Item)] += times * ingredient.Quantity
+		}
+	}
+
+	for _, crafted := range order {
+		plan.Steps = append(plan.Steps, CraftStep{Recipe: r.RecipeTitle(crafted), Item: crafted, Times: crafts[crafted]})
+	}
+	for name, n := range need {
+		if _, crafted := crafts[name]; !crafted {
+			plan.ShoppingList = append(plan.ShoppingList, Ingredient{Item: name, Quantity: n})
+		}
+	}
+	sort.Slice(plan.ShoppingList, func(i, j int) bool { return plan.ShoppingList[i].Item < plan.ShoppingList[j].Item })
+
+	return plan, nil
+}
+
+// recipe returns the recipe for an item, or nil if the item has none.
+func (r *RecipeResolver) recipe(item string) (*Recipe, error) {
+	recipe, err := r.Service.GetRecipe(r.RecipeTitle(item))
+	if errors.Is(err, ErrPageNotFound) || errors.Is(err, ErrNoInfobox) {
+		recipe, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return recipe, nil
+}
@@ -0,0 +1,415 @@
+package wizlib
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// TemplateMode controls how a Renderer outputs templates it has no function for.
+type TemplateMode int
+
+const (
+	// TemplateDrop removes templates from the output.
+	TemplateDrop TemplateMode = iota
+	// TemplateName replaces templates with their name in italics.
+	TemplateName
+	// TemplateArgs replaces templates with their positional arguments, e.g. {{Icon|Fire}} becomes "Fire".
+	TemplateArgs
+)
+
+// Renderer converts wikitext into Discord-flavored Markdown or plain text.
+type Renderer struct {
+	Templates TemplateMode
+	// TemplateFuncs renders specific templates by name, overriding Templates.
+	TemplateFuncs map[string]func(t Template) string
+	// LinkBase turns internal links into masked Markdown links when set.
+	LinkBase string
+	// MaxLength limits the output to this many characters; zero means unlimited.
+	MaxLength int
+	Ellipsis  string
+}
+
+// NewRenderer creates a new instance of Renderer linking to the Wizard101 Central wiki.
+// Use NewProfileRenderer for other wikis.
+func NewRenderer() *Renderer {
+	return &Renderer{
+		Templates:     TemplateDrop,
+		TemplateFuncs: make(map[string]func(t Template) string),
+		LinkBase:      Wizard101Profile.LinkBase,
+		Ellipsis:      "…",
+	}
+}
+
+// RenderMarkdown converts wikitext into Discord-flavored Markdown using the default renderer.
+func RenderMarkdown(wikiText string) string {
+	return NewRenderer().Markdown(wikiText)
+}
+
+// RenderPlainText converts wikitext into plain text using the default renderer.
+func RenderPlainText(wikiText string) string {
+	return NewRenderer().PlainText(wikiText)
+}
+
+// Markdown converts wikitext into Discord-flavored Markdown.
+func (r *Renderer) Markdown(wikiText string) string {
+	return r.truncate(r.render(wikiText, true))
+}
+
+// PlainText converts wikitext into plain text.
+func (r *Renderer) PlainText(wikiText string) string {
+	return r.truncate(r.render(wikiText, false))
+}
+
+var (
+	commentRegex      = regexp.MustCompile(`(?s)<!--.*?-->`)
+	refRegex          = regexp.MustCompile(`(?is)<ref[^>/]*/>|<ref[^>]*>.*?</ref>`)
+	behaviorRegex     = regexp.MustCompile(`__[A-Z]+__`)
+	breakRegex        = regexp.MustCompile(`(?i)<br\s*/?>`)
+	tagRegex          = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	headingRegex      = regexp.MustCompile(`^(={1,6})\s*(.*?)\s*={1,6}\s*$`)
+	internalLinkRegex = regexp.MustCompile(`\[\[([^\[\]|]*)(?:\|([^\[\]]*))?\]\]([a-z]*)`)
+	externalLinkRegex = regexp.MustCompile(`\[((?:https?:)?//[^\s\]]+)(?:\s+([^\]]*))?\]`)
+	emphasisRegex     = regexp.MustCompile(`'{2,5}`)
+	blankLinesRegex   = regexp.MustCompile(`\n{3,}`)
+	markdownLinkRegex = regexp.MustCompile(`\[[^\]]*\]\(<[^>]*>\)`)
+)
+
+// render converts wikitext, producing Markdown when markdown is set and plain text otherwise.
+func (r *Renderer) render(wikiText string, markdown bool) string {
+	text := commentRegex.ReplaceAllString(wikiText, "")
+	text = refRegex.ReplaceAllString(text, "")
+	text = behaviorRegex.ReplaceAllString(text, "")
+	text = r.renderTemplates(text, markdown)
+	// Tables are swapped for placeholders so that line rendering leaves their layout alone.
+	text, tables := r.renderTables(text, markdown)
+	text = breakRegex.ReplaceAllString(text, "\n")
+	text = tagRegex.ReplaceAllString(text, "")
+	text = r.renderLinks(text, markdown)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = r.renderLine(line, markdown)
+	}
+	text = strings.Join(lines, "\n")
+	for i, table := range tables {
+		text = strings.Replace(text, tablePlaceholder(i), table, 1)
+	}
+
+	text = html.UnescapeString(text)
+	text = blankLinesRegex.ReplaceAllString(text, "\n\n")
+
+	return strings.TrimSpace(text)
+}
+
+// renderTemplates replaces every top-level template call.
+func (r *Renderer) renderTemplates(text string, markdown bool) string {
+	templates := ParseTemplates(text)
+	if len(templates) == 0 {
+		return text
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, t := range templates {
+		sb.WriteString(text[last:t.Start])
+		sb.WriteString(r.renderTemplate(t, markdown))
+		last = t.End
+	}
+	sb.WriteString(text[last:])
+
+	return sb.String()
+}
+
+// renderTemplate renders a single template call.
+func (r *Renderer) renderTemplate(t Template, markdown bool) string {
+	for name, fn := range r.TemplateFuncs {
+		if strings.EqualFold(name, t.Name) {
+			return fn(t)
+		}
+	}
+
+	switch r.Templates {
+	case TemplateName:
+		if markdown {
+			return "*" + t.Name + "*"
+		}
+		return t.Name
+	case TemplateArgs:
+		args := t.Positional()
+		for i, arg := range args {
+			args[i] = r.renderTemplates(arg, markdown)
+		}
+		return strings.Join(args, " ")
+	default:
+		return ""
+	}
+}
+
+// renderTables converts {| ... |} tables into aligned code blocks, or tab-separated lines for plain text.
+// The tables are replaced by placeholders in the returned text and rendered separately.
+func (r *Renderer) renderTables(text string, markdown bool) (string, []string) {
+	var tables []string
+	for _, raw := range findWikiTables(text) {
+		table := parseWikiTable(raw, plainCell)
+
+		var rows [][]string
+		if len(table.Headers) > 0 && len(table.Rows) > 0 && !table.numbered {
+			rows = append(rows, table.Headers)
+		}
+		for _, row := range table.Rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = cell.Text
+			}
+			rows = append(rows, cells)
+		}
+
+		text = strings.Replace(text, raw, tablePlaceholder(len(tables)), 1)
+		tables = append(tables, strings.Join(formatTable(table.Caption, rows, markdown), "\n"))
+	}
+
+	return text, tables
+}
+
+// tablePlaceholder returns the marker standing in for the i-th rendered table.
+func tablePlaceholder(i int) string {
+	return fmt.Sprintf("\x00table%d\x00", i)
+}
+
+// formatTable lays out table rows as lines of text.
+func formatTable(caption string, rows [][]string, markdown bool) []string {
+	var nonEmpty [][]string
+	widths := []int{}
+	for _, row := range rows {
+		if len(row) == 0 {
+			continue
+		}
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+		nonEmpty = append(nonEmpty, row)
+	}
+
+	var out []string
+	if caption != "" {
+		if markdown {
+			caption = "**" + caption + "**"
+		}
+		out = append(out, caption)
+	}
+	if markdown {
+		out = append(out, "```")
+	}
+	for _, row := range nonEmpty {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			if markdown && i < len(row)-1 {
+				cell += strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			}
+			cells[i] = cell
+		}
+		if markdown {
+			out = append(out, strings.TrimRight(strings.Join(cells, " | "), " "))
+		} else {
+			out = append(out, strings.Join(cells, "\t"))
+		}
+	}
+	if markdown {
+		out = append(out, "```")
+	}
+
+	return out
+}
+
+// plainCell strips link and emphasis markup from a table cell, since code blocks do not render Markdown.
+func plainCell(cell string) string {
+	cell = internalLinkRegex.ReplaceAllStringFunc(cell, func(link string) string {
+		m := internalLinkRegex.FindStringSubmatch(link)
+		if isMediaLink(m[1]) {
+			return ""
+		}
+		if m[2] != "" {
+			return m[2] + m[3]
+		}
+		return strings.TrimPrefix(m[1], ":") + m[3]
+	})
+	cell = breakRegex.ReplaceAllString(cell, " ")
+	cell = tagRegex.ReplaceAllString(cell, "")
+	return strings.TrimSpace(emphasisRegex.ReplaceAllString(cell, ""))
+}
+
+// renderLinks converts internal and external links.
+func (r *Renderer) renderLinks(text string, markdown bool) string {
+	text = internalLinkRegex.ReplaceAllStringFunc(text, func(link string) string {
+		m := internalLinkRegex.FindStringSubmatch(link)
+		target := strings.TrimSpace(m[1])
+		if isMediaLink(target) {
+			return ""
+		}
+		target = strings.TrimPrefix(target, ":")
+
+		label := m[2]
+		if label == "" {
+			label = target
+		}
+		label += m[3]
+
+		if !markdown || r.LinkBase == "" || target == "" {
+			return label
+		}
+		return fmt.Sprintf("[%s](<%s%s>)", label, r.LinkBase, url.PathEscape(strings.ReplaceAll(target, " ", "_")))
+	})
+
+	return externalLinkRegex.ReplaceAllStringFunc(text, func(link string) string {
+		m := externalLinkRegex.FindStringSubmatch(link)
+		if m[2] == "" {
+			return m[1]
+		}
+		if !markdown {
+			return m[2]
+		}
+		return fmt.Sprintf("[%s](<%s>)", m[2], m[1])
+	})
+}
+
+// isMediaLink reports whether a link target embeds a file or assigns a category.
+func isMediaLink(target string) bool {
+	lower := strings.ToLower(strings.TrimSpace(target))
+	for _, prefix := range []string{"file:", "image:", "category:", "media:"} {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderLine converts headings, lists and emphasis on a single line.
+func (r *Renderer) renderLine(line string, markdown bool) string {
+	if m := headingRegex.FindStringSubmatch(line); m != nil {
+		title := emphasisRegex.ReplaceAllString(m[2], "")
+		if !markdown {
+			return title
+		}
+		switch len(m[1]) {
+		case 1:
+			return "# " + title
+		case 2:
+			return "## " + title
+		case 3:
+			return "### " + title
+		default:
+			return "**" + title + "**"
+		}
+	}
+
+	prefix := ""
+	if n := len(line) - len(strings.TrimLeft(line, "*#:;")); n > 0 {
+		marker := line[n-1]
+		indent := strings.Repeat("  ", n-1)
+		line = strings.TrimSpace(line[n:])
+		switch marker {
+		case '*':
+			prefix = indent + "- "
+		case '#':
+			prefix = indent + "1. "
+		case ':':
+			prefix = indent + "  "
+		case ';':
+			if markdown {
+				line = "**" + line + "**"
+			}
+			prefix = indent
+		}
+		if !markdown {
+			prefix = strings.Replace(prefix, "- ", "• ", 1)
+		}
+	}
+
+	return prefix + r.renderEmphasis(line, markdown)
+}
+
+// renderEmphasis converts runs of apostrophes into Markdown emphasis, or removes them for plain text.
+func (r *Renderer) renderEmphasis(line string, markdown bool) string {
+	if !markdown {
+		return emphasisRegex.ReplaceAllString(line, "")
+	}
+
+	return emphasisRegex.ReplaceAllStringFunc(line, func(quotes string) string {
+		switch len(quotes) {
+		case 2:
+			return "*"
+		case 3:
+			return "**"
+		case 5:
+			return "***"
+		default:
+			return quotes
+		}
+	})
+}
+
+// truncate shortens text to MaxLength characters, preferring paragraph, sentence and word boundaries.
+func (r *Renderer) truncate(text string) string {
+	if r.MaxLength <= 0 || utf8.RuneCountInString(text) <= r.MaxLength {
+		return text
+	}
+
+	limit := r.MaxLength - utf8.RuneCountInString(r.Ellipsis)
+	if limit <= 0 {
+		return string([]rune(text)[:r.MaxLength])
+	}
+	// Leave room for closing an open code block.
+	if strings.Contains(text, "```") {
+		limit -= 4
+	}
+	if limit <= 0 {
+		return string([]rune(text)[:r.MaxLength])
+	}
+
+	cut := string([]rune(text)[:limit])
+	// Avoid cutting very short: only accept boundaries in the last half of the allowed text.
+	minimum := len(cut) / 2
+	switch {
+	case strings.LastIndex(cut, "\n\n") > minimum:
+		cut = cut[:strings.LastIndex(cut, "\n\n")]
+	case lastSentenceEnd(cut) > minimum:
+		cut = cut[:lastSentenceEnd(cut)]
+	case strings.LastIndexAny(cut, " \n") > minimum:
+		cut = cut[:strings.LastIndexAny(cut, " \n")]
+	}
+
+	// Never end inside a Markdown link, which would leave a broken "[label](<url" behind.
+	for _, span := range markdownLinkRegex.FindAllStringIndex(text, -1) {
+		if span[0] < len(cut) && len(cut) < span[1] {
+			cut = cut[:span[0]]
+			break
+		}
+	}
+
+	cut = strings.TrimRight(cut, " \n")
+	if strings.Count(cut, "```")%2 == 1 {
+		return cut + "\n```" + r.Ellipsis
+	}
+
+	return cut + r.Ellipsis
+}
+
+// lastSentenceEnd returns the offset just past the last sentence-ending punctuation in s, or -1.
+func lastSentenceEnd(s string) int {
+	end := -1
+	for _, terminator := range []string{". ", "! ", "? ", ".\n", "!\n", "?\n"} {
+		if i := strings.LastIndex(s, terminator); i >= 0 && i+1 > end {
+			end = i + 1
+		}
+	}
+	return end
+}
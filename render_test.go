@@ -0,0 +1,22 @@
+package wizlib
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRendererTruncateKeepsLinks(t *testing.T) {
+	r := NewRenderer()
+	text := "See " + strings.Repeat("word ", 4) + "[[Fire Dragon|the dragon]] for details."
+	full := r.Markdown(text)
+	linkEnd := strings.Index(full, ">)")
+
+	r.MaxLength = linkEnd - 2
+	got := r.Markdown(text)
+	if strings.Contains(got, "[the dragon]") || strings.Contains(got, "](<") {
+		t.Errorf("Markdown() = %q, cut inside a link", got)
+	}
+	if !strings.HasSuffix(got, r.Ellipsis) {
+		t.Errorf("Markdown() = %q, want ellipsis", got)
+	}
+}
@@ -0,0 +1,358 @@
+package wizlib
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Value types reported by schema inference.
+const (
+	ValueInteger  = "integer"
+	ValueNumber   = "number"
+	ValuePercent  = "percent"
+	ValueBoolean  = "boolean"
+	ValueLink     = "link"
+	ValueList     = "list"
+	ValueTemplate = "template"
+	ValueText     = "text"
+)
+
+// FieldSchema describes one infobox key as observed across the sampled pages.
+type FieldSchema struct {
+	Key       string         `json:"key"`
+	Variants  []string       `json:"variants,omitempty"`
+	Count     int            `json:"count"`
+	Frequency float64        `json:"frequency"`
+	Types     map[string]int `json:"types"`
+	Examples  []string       `json:"examples,omitempty"`
+}
+
+// TemplateSchema describes the keys observed for one template.
+type TemplateSchema struct {
+	Name   string         `json:"name"`
+	Pages  int            `json:"pages"`
+	Fields []*FieldSchema `json:"fields"`
+}
+
+// Field returns the schema of a key, matching spelling variants.
+func (t *TemplateSchema) Field(key string) (*FieldSchema, bool) {
+	key = normalizeKey(key)
+	for _, field := range t.Fields {
+		if normalizeKey(field.Key) == key {
+			return field, true
+		}
+	}
+	return nil, false
+}
+
+// KeyIssue reports a key on a page that is rare for its template and possibly misspelled.
+type KeyIssue struct {
+	Page       string `json:"page"`
+	Template   string `json:"template"`
+	Key        string `json:"key"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// SchemaReport is the result of inferring infobox schemas over a category.
+type SchemaReport struct {
+	Category  string            `json:"category,omitempty"`
+	Sampled   int               `json:"sampled"`
+	Templates []*TemplateSchema `json:"templates"`
+	Issues    []KeyIssue        `json:"issues,omitempty"`
+}
+
+// Template returns the schema of a template by name, ignoring case.
+func (r *SchemaReport) Template(name string) (*TemplateSchema, bool) {
+	for _, t := range r.Templates {
+		if strings.EqualFold(t.Name, normalizeTitle(name)) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *SchemaReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// SchemaInferrer samples the pages of a category and infers the schema of the templates they use.
+type SchemaInferrer struct {
+	Service *WikiService
+	// SampleSize is the number of pages to sample, spread evenly over the category; zero samples every page.
+	SampleSize int
+	// Templates restricts inference to the named templates; by default every template with named parameters is included.
+	Templates []string
+	// Known lists the expected keys of a template, keyed by template name; keys outside it are reported.
+	Known map[string][]string
+	// MinFrequency is the share of pages below which a key that is not known is reported as unusual.
+	MinFrequency float64
+	// MaxExamples is the number of distinct example values kept per key.
+	MaxExamples int
+}
+
+// NewSchemaInferrer creates a new instance of SchemaInferrer.
+func NewSchemaInferrer(service *WikiService) *SchemaInferrer {
+	return &SchemaInferrer{
+		Service:      service,
+		SampleSize:   100,
+		Known:        make(map[string][]string),
+		MinFrequency: 0.05,
+		MaxExamples:  3,
+	}
+}
+
+// keyUse records a key as it appeared on a sampled page.
+type keyUse struct {
+	page, template, key string
+}
+
+// Infer samples the category and returns the observed schemas and key issues.
+func (i *SchemaInferrer) Infer(category string) (*SchemaReport, error) {
+	titles, err := i.Service.CategoryMembers(category)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := i.InferPages(sample(titles, i.SampleSize))
+	if err != nil {
+		return nil, err
+	}
+	report.Category = category
+
+	return report, nil
+}
+
+// InferPages infers the schemas of the templates used by the given pages.
+// Pages that are missing when fetched are skipped.
+func (i *SchemaInferrer) InferPages(titles []string) (*SchemaReport, error) {
+	report := &SchemaReport{}
+	schemas := make(map[string]*TemplateSchema)
+	fields := make(map[string]map[string]*FieldSchema)
+	spellings := make(map[string]map[string]map[string]int)
+	var uses []keyUse
+
+	for _, title := range titles {
+		wiki, err := i.Service.GetWikiText(title)
+		if errors.Is(err, ErrPageNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		report.Sampled++
+
+		seen := make(map[string]bool)
+		for _, t := range ParseTemplates(wiki.Parse.Content) {
+			name := normalizeTitle(t.Name)
+			if !i.wanted(name, t) || seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			schema, ok := schemas[name]
+			if !ok {
+				schema = &TemplateSchema{Name: name}
+				schemas[name] = schema
+				fields[name] = make(map[string]*FieldSchema)
+				spellings[name] = make(map[string]map[string]int)
+			}
+			schema.Pages++
+
+			// A key repeated on the page counts once; MediaWiki uses its last value.
+			last := make(map[string]int)
+			for n, p := range t.Params {
+				if p.Named {
+					last[normalizeKey(p.Key)] = n
+				}
+			}
+
+			for n, p := range t.Params {
+				if !p.Named {
+					continue
+				}
+				key := normalizeKey(p.Key)
+				if last[key] != n {
+					continue
+				}
+				field, ok := fields[name][key]
+				if !ok {
+					field = &FieldSchema{Types: make(map[string]int)}
+					fields[name][key] = field
+					spellings[name][key] = make(map[string]int)
+				}
+				field.Count++
+				spellings[name][key][strings.TrimSpace(p.Key)]++
+				uses = append(uses, keyUse{page: wiki.Parse.Title, template: name, key: key})
+
+				value := strings.TrimSpace(p.Value)
+				if value == "" {
+					continue
+				}
+				field.Types[valueType(value)]++
+				if len(field.Examples) < i.MaxExamples && !containsString(field.Examples, value) {
+					field.Examples = append(field.Examples, value)
+				}
+			}
+		}
+	}
+
+	for name, schema := range schemas {
+		for key, field := range fields[name] {
+			field.Key, field.Variants = preferredSpelling(spellings[name][key])
+			field.Frequency = float64(field.Count) / float64(schema.Pages)
+			schema.Fields = append(schema.Fields, field)
+		}
+		sort.Slice(schema.Fields, func(a, b int) bool {
+			if schema.Fields[a].Count != schema.Fields[b].Count {
+				return schema.Fields[a].Count > schema.Fields[b].Count
+			}
+			return schema.Fields[a].Key < schema.Fields[b].Key
+		})
+		report.Templates = append(report.Templates, schema)
+	}
+	sort.Slice(report.Templates, func(a, b int) bool {
+		if report.Templates[a].Pages != report.Templates[b].Pages {
+			return report.Templates[a].Pages > report.Templates[b].Pages
+		}
+		return report.Templates[a].Name < report.Templates[b].Name
+	})
+
+	for _, use := range uses {
+		if issue, ok := i.check(use, schemas[use.template], fields[use.template]); ok {
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+
+	return report, nil
+}
+
+// wanted reports whether a template takes part in inference.
+func (i *SchemaInferrer) wanted(name string, t Template) bool {
+	if len(i.Templates) > 0 {
+		for _, wanted := range i.Templates {
+			if strings.EqualFold(normalizeTitle(wanted), name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, p := range t.Params {
+		if p.Named {
+			return true
+		}
+	}
+	return false
+}
+
+// check reports a key use that is neither known nor common for its template, with the closest accepted key.
+func (i *SchemaInferrer) check(use keyUse, schema *TemplateSchema, fields map[string]*FieldSchema) (KeyIssue, bool) {
+	var accepted []string
+	known := i.knownKeys(use.template)
+	if len(known) > 0 {
+		accepted = known
+	} else {
+		for key, field := range fields {
+			if float64(field.Count)/float64(schema.Pages) >= i.MinFrequency {
+				accepted = append(accepted, key)
+			}
+		}
+	}
+	sort.Strings(accepted)
+
+	if containsString(accepted, use.key) {
+		return KeyIssue{}, false
+	}
+
+	issue := KeyIssue{Page: use.page, Template: use.template, Key: fields[use.key].Key}
+	best := 3
+	for _, key := range accepted {
+		if d := editDistance(use.key, key, 2); d < best {
+			best = d
+			issue.Suggestion = key
+			if field, ok := fields[key]; ok {
+				issue.Suggestion = field.Key
+			}
+		}
+	}
+
+	return issue, true
+}
+
+// knownKeys returns the normalized expected keys of a template.
+func (i *SchemaInferrer) knownKeys(template string) []string {
+	var keys []string
+	for name, known := range i.Known {
+		if !strings.EqualFold(normalizeTitle(name), template) {
+			continue
+		}
+		for _, key := range known {
+			keys = append(keys, normalizeKey(key))
+		}
+	}
+	return keys
+}
+
+// sample returns up to n titles spread evenly over the list, or all of them when n is zero.
+func sample(titles []string, n int) []string {
+	if n <= 0 || len(titles) <= n {
+		return titles
+	}
+
+	sampled := make([]string, n)
+	for i := range sampled {
+		sampled[i] = titles[i*len(titles)/n]
+	}
+	return sampled
+}
+
+// preferredSpelling returns the most used spelling of a key and the other spellings seen.
+func preferredSpelling(counts map[string]int) (string, []string) {
+	spellings := sortedKeys(counts)
+	sort.SliceStable(spellings, func(a, b int) bool { return counts[spellings[a]] > counts[spellings[b]] })
+	return spellings[0], spellings[1:]
+}
+
+var (
+	integerValueRegex = regexp.MustCompile(`^[+-]?\d[\d,]*$`)
+	numberValueRegex  = regexp.MustCompile(`^[+-]?\d[\d,]*\.\d+$`)
+	percentValueRegex = regexp.MustCompile(`^[+-]?\d[\d,]*(?:\.\d+)?\s*%$`)
+	linkValueRegex    = regexp.MustCompile(`^\[\[[^\[\]]+\]\]$`)
+)
+
+// valueType classifies a raw infobox value.
+func valueType(value string) string {
+	switch {
+	case integerValueRegex.MatchString(value):
+		return ValueInteger
+	case numberValueRegex.MatchString(value):
+		return ValueNumber
+	case percentValueRegex.MatchString(value):
+		return ValuePercent
+	case isBoolValue(value):
+		return ValueBoolean
+	case linkValueRegex.MatchString(value):
+		return ValueLink
+	case strings.HasPrefix(value, "{{") && matchBraces(value, 0) == len(value):
+		return ValueTemplate
+	case len(splitList(value)) > 1 && !strings.Contains(value, ". "):
+		return ValueList
+	}
+	return ValueText
+}
+
+// isBoolValue reports whether a value is one of the ways editors write yes or no.
+func isBoolValue(value string) bool {
+	switch strings.ToLower(value) {
+	case "yes", "no", "y", "n", "true", "false":
+		return true
+	}
+	return false
+}
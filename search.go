@@ -0,0 +1,383 @@
+package wizlib
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SearchResult represents a page matching a search query.
+type SearchResult struct {
+	Title string  `json:"title"`
+	Score float64 `json:"score"`
+}
+
+// indexedDoc holds the term frequencies of a single page, per field.
+type indexedDoc struct {
+	Title   string         `json:"title"`
+	RevID   int64          `json:"revid"`
+	Name    map[string]int `json:"name"`
+	Infobox map[string]int `json:"infobox"`
+	Body    map[string]int `json:"body"`
+}
+
+// posting records how often a term occurs in each field of a page.
+type posting struct {
+	title, infobox, body int
+}
+
+// SearchIndex is an in-process inverted index over page titles, infobox fields and body text.
+// It supports exact, prefix and fuzzy term matching with per-field boosts.
+type SearchIndex struct {
+	TitleBoost   float64
+	InfoboxBoost float64
+	BodyBoost    float64
+
+	mu       sync.RWMutex
+	docs     map[string]*indexedDoc
+	postings map[string]map[string]*posting
+	terms    []string
+	byLength map[int][]string
+	dirty    bool
+}
+
+// NewSearchIndex creates a new, empty instance of SearchIndex.
+func NewSearchIndex() *SearchIndex {
+	return &SearchIndex{
+		TitleBoost:   3,
+		InfoboxBoost: 2,
+		BodyBoost:    1,
+		docs:         make(map[string]*indexedDoc),
+		postings:     make(map[string]map[string]*posting),
+	}
+}
+
+// LoadSearchIndex reads an index previously written with Save.
+func LoadSearchIndex(path string) (*SearchIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []*indexedDoc
+	if err := json.Unmarshal(data, &docs); err != nil {
+		return nil, err
+	}
+
+	idx := NewSearchIndex()
+	for _, doc := range docs {
+		idx.insert(doc)
+	}
+
+	return idx, nil
+}
+
+// Save writes the index to path.
+func (idx *SearchIndex) Save(path string) error {
+	idx.mu.RLock()
+	docs := make([]*indexedDoc, 0, len(idx.docs))
+	for _, doc := range idx.docs {
+		docs = append(docs, doc)
+	}
+	idx.mu.RUnlock()
+
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Title < docs[j].Title })
+
+	data, err := json.Marshal(docs)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, data)
+}
+
+// Len returns the number of indexed pages.
+func (idx *SearchIndex) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return len(idx.docs)
+}
+
+// Add indexes a page, replacing any older revision of it.
+// Pages whose revision is already indexed are skipped.
+func (idx *SearchIndex) Add(page StoredPage) {
+	title := normalizeTitle(page.Title)
+
+	idx.mu.RLock()
+	existing, ok := idx.docs[title]
+	idx.mu.RUnlock()
+	if ok && page.RevID != 0 && existing.RevID == page.RevID {
+		return
+	}
+
+	infobox := page.Infobox
+	if infobox == nil {
+		infobox = extractInfoboxData(extractInfobox(page.Content))
+	}
+
+	doc := &indexedDoc{
+		Title:   title,
+		RevID:   page.RevID,
+		Name:    termFrequencies(title),
+		Infobox: make(map[string]int),
+		Body:    termFrequencies(RenderPlainText(page.Content)),
+	}
+	for key, value := range infobox {
+		for term, n := range termFrequencies(key + " " + value) {
+			doc.Infobox[term] += n
+		}
+	}
+
+	idx.insert(doc)
+}
+
+// Remove drops a page from the index.
+func (idx *SearchIndex) Remove(title string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(normalizeTitle(title))
+}
+
+// Attach keeps the index up to date with every page the service fetches.
+func (idx *SearchIndex) Attach(s *WikiService) {
+	s.OnFetch(idx.Add)
+}
+
+// IndexStore indexes every page of a local page store.
+func (idx *SearchIndex) IndexStore(store *DiskPageStore) error {
+	titles, err := store.Titles()
+	if err != nil {
+		return err
+	}
+
+	for _, title := range titles {
+		page, err := store.Get(title)
+		if err != nil {
+			return err
+		}
+		idx.Add(page)
+	}
+
+	return nil
+}
+
+// Search returns up to limit pages matching the query, best match first.
+// Each query term matches indexed terms exactly, by prefix, or within a small edit distance.
+func (idx *SearchIndex) Search(query string, limit int) []SearchResult {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if idx.dirty {
+		idx.rebuildTerms()
+	}
+
+	total := float64(len(idx.docs))
+	scores := make(map[string]float64)
+
+	for _, queryTerm := range tokenize(query) {
+		// Keep only the best match of this query term for each page.
+		best := make(map[string]float64)
+		for term, weight := range idx.expand(queryTerm) {
+			docs := idx.postings[term]
+			idf := math.Log(1 + total/float64(len(docs)))
+			for title, p := range docs {
+				tf := idx.TitleBoost*damp(p.title) + idx.InfoboxBoost*damp(p.infobox) + idx.BodyBoost*damp(p.body)
+				if score := weight * tf * idf; score > best[title] {
+					best[title] = score
+				}
+			}
+		}
+		for title, score := range best {
+			scores[title] += score
+		}
+	}
+
+	results := make([]SearchResult, 0, len(scores))
+	for title, score := range scores {
+		results = append(results, SearchResult{Title: title, Score: score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Title < results[j].Title
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+// rebuildTerms refreshes the sorted term list and the terms grouped by length;
+// the caller must hold the write lock.
+func (idx *SearchIndex) rebuildTerms() {
+	idx.terms = idx.terms[:0]
+	idx.byLength = make(map[int][]string)
+	for term := range idx.postings {
+		idx.terms = append(idx.terms, term)
+		n := utf8.RuneCountInString(term)
+		idx.byLength[n] = append(idx.byLength[n], term)
+	}
+	sort.Strings(idx.terms)
+	idx.dirty = false
+}
+
+// expand returns the indexed terms matching a query term, weighted by match quality.
+func (idx *SearchIndex) expand(queryTerm string) map[string]float64 {
+	matches := make(map[string]float64)
+	if _, ok := idx.postings[queryTerm]; ok {
+		matches[queryTerm] = 1
+	}
+
+	n := utf8.RuneCountInString(queryTerm)
+	if n >= 2 {
+		i := sort.SearchStrings(idx.terms, queryTerm)
+		for ; i < len(idx.terms) && strings.HasPrefix(idx.terms[i], queryTerm); i++ {
+			if _, ok := matches[idx.terms[i]]; !ok {
+				matches[idx.terms[i]] = 0.7
+			}
+		}
+	}
+
+	maxDistance := 1
+	if n >= 8 {
+		maxDistance = 2
+	}
+	if n >= 4 {
+		// Only terms whose length is within the distance can match.
+		for length := n - maxDistance; length <= n+maxDistance; length++ {
+			for _, term := range idx.byLength[length] {
+				if _, ok := matches[term]; ok {
+					continue
+				}
+				if editDistance(queryTerm, term, maxDistance) <= maxDistance {
+					matches[term] = 0.5
+				}
+			}
+		}
+	}
+
+	return matches
+}
+
+// insert adds a document to the index, replacing an existing one with the same title.
+func (idx *SearchIndex) insert(doc *indexedDoc) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(doc.Title)
+	idx.docs[doc.Title] = doc
+
+	add := func(freqs map[string]int, set func(p *posting, n int)) {
+		for term, n := range freqs {
+			docs, ok := idx.postings[term]
+			if !ok {
+				docs = make(map[string]*posting)
+				idx.postings[term] = docs
+				idx.dirty = true
+			}
+			p, ok := docs[doc.Title]
+			if !ok {
+				p = &posting{}
+				docs[doc.Title] = p
+			}
+			set(p, n)
+		}
+	}
+	add(doc.Name, func(p *posting, n int) { p.title = n })
+	add(doc.Infobox, func(p *posting, n int) { p.infobox = n })
+	add(doc.Body, func(p *posting, n int) { p.body = n })
+}
+
+// remove drops a document from the postings; the caller must hold the write lock.
+func (idx *SearchIndex) remove(title string) {
+	doc, ok := idx.docs[title]
+	if !ok {
+		return
+	}
+
+	for _, freqs := range []map[string]int{doc.Name, doc.Infobox, doc.Body} {
+		for term := range freqs {
+			delete(idx.postings[term], title)
+			if len(idx.postings[term]) == 0 {
+				delete(idx.postings, term)
+				idx.dirty = true
+			}
+		}
+	}
+	delete(idx.docs, title)
+}
+
+// tokenize splits text into lower-case terms made of letters and digits.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// termFrequencies counts how often each term occurs in text.
+func termFrequencies(text string) map[string]int {
+	freqs := make(map[string]int)
+	for _, term := range tokenize(text) {
+		freqs[term]++
+	}
+	return freqs
+}
+
+// damp dampens term frequencies so that repeated words do not dominate the score.
+func damp(n int) float64 {
+	if n == 0 {
+		return 0
+	}
+	return 1 + math.Log(float64(n))
+}
+
+// editDistance returns the Levenshtein distance between a and b, or max+1 once it exceeds max.
+func editDistance(a, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > max || -d > max {
+		return max + 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j-1] + cost
+			if prev[j]+1 < curr[j] {
+				curr[j] = prev[j] + 1
+			}
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+			if curr[j] < rowMin {
+				rowMin = curr[j]
+			}
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
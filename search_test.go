@@ -0,0 +1,27 @@
+package wizlib
+
+import "testing"
+
+func TestSearchIndexBodyIsPlainText(t *testing.T) {
+	idx := NewSearchIndex()
+	idx.Add(StoredPage{Title: "Fire Hat", Content: "{{ItemInfobox\n|school = Fire\n}}\nA hat sold in [[Olde Town|the town]].<ref>Patch notes</ref>"})
+
+	for _, query := range []string{"iteminfobox", "olde", "patch"} {
+		if results := idx.Search(query, 10); len(results) != 0 {
+			t.Errorf("Search(%q) = %v, want no results", query, results)
+		}
+	}
+	if results := idx.Search("town", 10); len(results) != 1 {
+		t.Errorf("Search(town) = %v, want Fire Hat", results)
+	}
+}
+
+func TestSearchIndexFuzzyCountsRunes(t *testing.T) {
+	idx := NewSearchIndex()
+	idx.Add(StoredPage{Title: "Ela"})
+
+	// "éla" is three runes, too short for fuzzy matching, though it is four bytes.
+	if results := idx.Search("éla", 10); len(results) != 0 {
+		t.Errorf("Search(éla) = %v, want no results", results)
+	}
+}
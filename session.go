@@ -0,0 +1,270 @@
+package wizlib
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// LoginError is returned when the wiki rejects a login.
+type LoginError struct {
+	Result string
+	Reason string
+}
+
+func (e *LoginError) Error() string {
+	if e.Reason == "" {
+		return "mediawiki: login " + e.Result
+	}
+	return fmt.Sprintf("mediawiki: login %s: %s", e.Result, e.Reason)
+}
+
+// credentials are kept after a successful login to re-authenticate when the session expires.
+type credentials struct {
+	username string
+	password string
+}
+
+// sessionErrorCodes lists the API error codes reporting that the request was made without a valid session.
+// assertbotfailed is not listed: it is also returned to logged in accounts without the bot right.
+var sessionErrorCodes = map[string]bool{
+	"assertuserfailed":      true,
+	"assertnameduserfailed": true,
+	"notloggedin":           true,
+	"readapidenied":         true,
+}
+
+// Login signs in with a bot password, created on the wiki's Special:BotPasswords page, where username
+// has the form "User@BotName". The session cookie is kept in the client's cookie jar, and later
+// requests log in again on their own when the wiki reports that the session has expired.
+func (s *WikiService) Login(username, password string) error {
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+
+	if err := s.login(username, password); err != nil {
+		return err
+	}
+	s.credentials = &credentials{username: username, password: password}
+	return nil
+}
+
+// Logout ends the session and forgets the credentials.
+func (s *WikiService) Logout() error {
+	s.authMu.Lock()
+	s.credentials = nil
+	s.authMu.Unlock()
+
+	token, err := s.token("csrf")
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	params.Set("action", "logout")
+	params.Set("token", token)
+	body, err := s.send(params, true)
+	if err != nil {
+		return err
+	}
+	return apiError(body)
+}
+
+// LoggedIn reports whether the service has logged in.
+func (s *WikiService) LoggedIn() bool {
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+
+	return s.credentials != nil
+}
+
+// login performs the action=login flow: fetch a login token, then post the credentials with it.
+// The caller must hold authMu.
+func (s *WikiService) login(username, password string) error {
+	if s.Client == nil {
+		return ErrOffline
+	}
+	s.Client.ensureJar()
+
+	params := url.Values{}
+	params.Set("action", "query")
+	params.Set("meta", "tokens")
+	params.Set("type", "login")
+	body, err := s.send(params, false)
+	if err != nil {
+		return err
+	}
+
+	var tokens struct {
+		Query struct {
+			Tokens struct {
+				LoginToken string `json:"logintoken"`
+			} `json:"tokens"`
+		} `json:"query"`
+		Error *APIError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &tokens); err != nil {
+		return err
+	}
+	if tokens.Error != nil {
+		return tokens.Error
+	}
+
+	params = url.Values{}
+	params.Set("action", "login")
+	params.Set("lgname", username)
+	params.Set("lgpassword", password)
+	params.Set("lgtoken", tokens.Query.Tokens.LoginToken)
+	body, err = s.send(params, true)
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Login struct {
+			Result string `json:"result"`
+			Reason string `json:"reason"`
+		} `json:"login"`
+		Error *APIError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	if response.Login.Result != "Success" {
+		return &LoginError{Result: response.Login.Result, Reason: response.Login.Reason}
+	}
+
+	return nil
+}
+
+// call performs an API request. When logged in, the request asserts the session, and if the wiki
+// reports that it has expired the service logs in again and retries once. A CSRF token sent with the request belonged to the expired session, so it is replaced with a
+// fresh one for the retry.
+func (s *WikiService) call(params url.Values, post bool) ([]byte, error) {
+	s.authMu.Lock()
+	creds := s.credentials
+	s.authMu.Unlock()
+
+	if creds != nil && params.Get("assert") == "" {
+		params.Set("assert", "user")
+	}
+
+	body, err := s.send(params, post)
+	if err != nil || creds == nil || !s.sessionExpired(body) {
+		return body, err
+	}
+
+	if err := s.relogin(creds); err != nil {
+		return nil, err
+	}
+	if params.Get("token") != "" {
+		token, err := s.token("csrf")
+		if err != nil {
+			return nil, err
+		}
+		params.Set("token", token)
+	}
+	return s.send(params, post)
+}
+
+// anonymous reports whether the wiki sees the current session as logged out.
+func (s *WikiService) anonymous() bool {
+	params := url.Values{}
+	params.Set("action", "query")
+	params.Set("meta", "userinfo")
+	body, err := s.send(params, false)
+	if err != nil {
+		return false
+	}
+
+	var response struct {
+		Query struct {
+			UserInfo struct {
+				Anon bool `json:"anon"`
+			} `json:"userinfo"`
+		} `json:"query"`
+		Error *APIError `json:"error"`
+	}
+	if json.Unmarshal(body, &response) != nil || response.Error != nil {
+		return false
+	}
+	return response.Query.UserInfo.Anon
+}
+
+// relogin logs in again, unless another request already did since creds were read.
+func (s *WikiService) relogin(creds *credentials) error {
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+
+	if s.credentials != creds {
+		return nil
+	}
+	if err := s.login(creds.username, creds.password); err != nil {
+		return err
+	}
+	s.credentials = &credentials{username: creds.username, password: creds.password}
+	return nil
+}
+
+// token fetches an action token, such as "csrf", for the current session.
+func (s *WikiService) token(kind string) (string, error) {
+	params := url.Values{}
+	params.Set("action", "query")
+	params.Set("meta", "tokens")
+	params.Set("type", kind)
+
+	body, err := s.query(params)
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Query struct {
+			Tokens map[string]string `json:"tokens"`
+		} `json:"query"`
+		Error *APIError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", err
+	}
+	if response.Error != nil {
+		return "", response.Error
+	}
+
+	token, ok := response.Query.Tokens[kind+"token"]
+	if !ok {
+		return "", fmt.Errorf("mediawiki: no %s token returned", kind)
+	}
+	return token, nil
+}
+
+// sessionExpired reports whether an API response says the request lacked a valid session.
+// A failed bot assertion only counts when meta=userinfo shows that the session is gone.
+func (s *WikiService) sessionExpired(body []byte) bool {
+	var response struct {
+		Error *APIError `json:"error"`
+	}
+	if json.Unmarshal(body, &response) != nil || response.Error == nil {
+		return false
+	}
+	if response.Error.Code == "assertbotfailed" {
+		return s.anonymous()
+	}
+	return sessionErrorCodes[response.Error.Code]
+}
+
+// apiError returns the error reported in an API response, if any.
+func apiError(body []byte) error {
+	var response struct {
+		Error *APIError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	return nil
+}
@@ -0,0 +1,340 @@
+package wizlib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakePage is a page held by fakeWiki.
+type fakePage struct {
+	PageID int64
+	RevID  int64
+	Text   string
+}
+
+// fakeSession is a session of fakeWiki, identified by a cookie.
+type fakeSession struct {
+	loggedIn bool
+	csrf     string
+}
+
+// fakeWiki is a minimal MediaWiki API stand-in supporting login, tokens, user info, search, parse and edit.
+type fakeWiki struct {
+	mu       sync.Mutex
+	user     string
+	password string
+	pages    map[string]*fakePage
+	sessions map[string]*fakeSession
+	next     int
+
+	// expireOnEdit ends every session just before the next edit is handled.
+	expireOnEdit bool
+	// badTokens is the number of edits answered with a badtoken error.
+	badTokens int
+	// noBotRight makes assert=bot fail for logged in sessions.
+	noBotRight bool
+
+	logins       int
+	edits        int
+	editRequests int
+	searches     int
+}
+
+// newFakeWiki starts a fakeWiki and returns a logged out service talking to it.
+func newFakeWiki(t *testing.T) (*fakeWiki, *WikiService) {
+	t.Helper()
+
+	w := &fakeWiki{
+		user:     "Bot@Test",
+		password: "secret",
+		pages:    map[string]*fakePage{"Fire Hat": {PageID: 7, RevID: 100, Text: "{{ItemInfobox\n|level = 10\n}}"}},
+		sessions: make(map[string]*fakeSession),
+	}
+	srv := httptest.NewServer(w)
+	t.Cleanup(srv.Close)
+
+	jar, _ := cookiejar.New(nil)
+	s := NewWikiService(&APIClient{Client: &http.Client{Jar: jar}})
+	s.Endpoint = srv.URL
+	return w, s
+}
+
+// expire ends every session, as when the wiki's session store drops them.
+func (w *fakeWiki) expire() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, session := range w.sessions {
+		session.loggedIn = false
+		session.csrf = ""
+	}
+}
+
+func (w *fakeWiki) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	r.ParseForm()
+	session := w.session(rw, r)
+	reply := func(v interface{}) { json.NewEncoder(rw).Encode(v) }
+	fail := func(code string) {
+		reply(map[string]interface{}{"error": map[string]string{"code": code, "info": code}})
+	}
+
+	if r.Form.Get("action") == "edit" {
+		w.editRequests++
+	}
+	if r.Form.Get("action") == "edit" && w.expireOnEdit {
+		w.expireOnEdit = false
+		for _, s := range w.sessions {
+			s.loggedIn, s.csrf = false, ""
+		}
+	}
+	switch r.Form.Get("assert") {
+	case "user", "bot":
+		if !session.loggedIn || r.Form.Get("assert") == "bot" && w.noBotRight {
+			fail("assert" + r.Form.Get("assert") + "failed")
+			return
+		}
+	}
+
+	switch r.Form.Get("action") {
+	case "query":
+		if r.Form.Get("prop") == "info" {
+			var pages []map[string]interface{}
+			for _, title := range strings.Split(r.Form.Get("titles"), "|") {
+				if page, ok := w.pages[title]; ok {
+					pages = append(pages, map[string]interface{}{"title": title, "lastrevid": page.RevID})
+				} else {
+					pages = append(pages, map[string]interface{}{"title": title, "missing": true})
+				}
+			}
+			reply(map[string]interface{}{"query": map[string]interface{}{"pages": pages}})
+			return
+		}
+		if r.Form.Get("meta") == "userinfo" {
+			info := map[string]interface{}{"id": 0, "name": "127.0.0.1", "anon": true}
+			if session.loggedIn {
+				info = map[string]interface{}{"id": 1, "name": w.user}
+			}
+			reply(map[string]interface{}{"query": map[string]interface{}{"userinfo": info}})
+			return
+		}
+		if r.Form.Get("list") == "search" {
+			w.searches++
+			var results []map[string]string
+			for title := range w.pages {
+				results = append(results, map[string]string{"title": title})
+			}
+			reply(map[string]interface{}{"query": map[string]interface{}{"search": results}})
+			return
+		}
+		switch r.Form.Get("type") {
+		case "login":
+			reply(map[string]interface{}{"query": map[string]interface{}{"tokens": map[string]string{"logintoken": "login+\\"}}})
+		case "csrf":
+			token := `+\`
+			if session.loggedIn {
+				if session.csrf == "" {
+					w.next++
+					session.csrf = fmt.Sprintf("csrf%d+\\", w.next)
+				}
+				token = session.csrf
+			}
+			reply(map[string]interface{}{"query": map[string]interface{}{"tokens": map[string]string{"csrftoken": token}}})
+		default:
+			fail("badrequest")
+		}
+
+	case "login":
+		if r.Method != http.MethodPost || r.Form.Get("lgtoken") != "login+\\" {
+			fail("badtoken")
+			return
+		}
+		if r.Form.Get("lgname") != w.user || r.Form.Get("lgpassword") != w.password {
+			reply(map[string]interface{}{"login": map[string]string{"result": "Failed", "reason": "Incorrect password"}})
+			return
+		}
+		session.loggedIn = true
+		w.logins++
+		reply(map[string]interface{}{"login": map[string]string{"result": "Success"}})
+
+	case "parse":
+		page, ok := w.pages[r.Form.Get("page")]
+		if !ok {
+			fail("missingtitle")
+			return
+		}
+		reply(map[string]interface{}{"parse": map[string]interface{}{
+			"title": r.Form.Get("page"), "pageid": page.PageID, "revid": page.RevID, "wikitext": page.Text,
+		}})
+
+	case "edit":
+		if r.Method != http.MethodPost || r.Form.Get("token") != session.csrf || session.csrf == "" {
+			fail("badtoken")
+			return
+		}
+		if w.badTokens > 0 {
+			w.badTokens--
+			session.csrf = ""
+			fail("badtoken")
+			return
+		}
+		title := r.Form.Get("title")
+		page, ok := w.pages[title]
+		if !ok {
+			page = &fakePage{PageID: int64(len(w.pages) + 1)}
+			w.pages[title] = page
+		}
+		if base, _ := strconv.ParseInt(r.Form.Get("baserevid"), 10, 64); base != 0 && base != page.RevID {
+			fail("editconflict")
+			return
+		}
+		old := page.RevID
+		page.RevID++
+		page.Text = r.Form.Get("text")
+		w.edits++
+		reply(map[string]interface{}{"edit": map[string]interface{}{
+			"result": "Success", "title": title, "pageid": page.PageID, "oldrevid": old, "newrevid": page.RevID,
+		}})
+
+	default:
+		fail("unknown_action")
+	}
+}
+
+// session returns the session of the request's cookie, starting a new one if needed.
+func (w *fakeWiki) session(rw http.ResponseWriter, r *http.Request) *fakeSession {
+	if cookie, err := r.Cookie("wikisession"); err == nil {
+		if session, ok := w.sessions[cookie.Value]; ok {
+			return session
+		}
+	}
+	w.next++
+	id := strconv.Itoa(w.next)
+	w.sessions[id] = &fakeSession{}
+	http.SetCookie(rw, &http.Cookie{Name: "wikisession", Value: id, Path: "/"})
+	return w.sessions[id]
+}
+
+func TestLogin(t *testing.T) {
+	_, s := newFakeWiki(t)
+
+	err := s.Login("Bot@Test", "wrong")
+	var loginErr *LoginError
+	if !errors.As(err, &loginErr) || loginErr.Result != "Failed" {
+		t.Fatalf("Login with a wrong password: got %v, want a LoginError", err)
+	}
+	if s.LoggedIn() {
+		t.Fatal("LoggedIn() after a failed login")
+	}
+
+	if err := s.Login("Bot@Test", "secret"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if !s.LoggedIn() {
+		t.Fatal("LoggedIn() = false after logging in")
+	}
+}
+
+func TestToken(t *testing.T) {
+	w, s := newFakeWiki(t)
+	if err := s.Login("Bot@Test", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := s.token("csrf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, session := range w.sessions {
+		if session.loggedIn && session.csrf != token {
+			t.Errorf("token = %q, want the session's token %q", token, session.csrf)
+		}
+	}
+}
+
+func TestReloginOnExpiredSession(t *testing.T) {
+	w, s := newFakeWiki(t)
+	if err := s.Login("Bot@Test", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	w.expire()
+
+	if _, err := s.token("csrf"); err != nil {
+		t.Fatalf("token after the session expired: %v", err)
+	}
+	if w.logins != 2 {
+		t.Errorf("logins = %d, want 2", w.logins)
+	}
+}
+
+func TestEditReloginRefreshesToken(t *testing.T) {
+	w, s := newFakeWiki(t)
+	if err := s.Login("Bot@Test", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	w.expireOnEdit = true
+
+	result, err := s.EditRevision("Fire Hat", "new text", "test", 100)
+	if err != nil {
+		t.Fatalf("EditRevision: %v", err)
+	}
+	if result.NewRevID != 101 || w.logins != 2 || w.edits != 1 {
+		t.Errorf("got revision %d after %d logins and %d edits, want 101, 2 and 1", result.NewRevID, w.logins, w.edits)
+	}
+	// The retry after logging in again must carry the new session's token.
+	if w.editRequests != 2 {
+		t.Errorf("edit requests = %d, want 2", w.editRequests)
+	}
+}
+
+func TestEditWithoutBotRight(t *testing.T) {
+	w, s := newFakeWiki(t)
+	if err := s.Login("Bot@Test", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	w.noBotRight = true
+
+	var apiErr *APIError
+	if _, err := s.EditRevision("Fire Hat", "new text", "test", 100); !errors.As(err, &apiErr) || apiErr.Code != "assertbotfailed" {
+		t.Fatalf("EditRevision: got %v, want assertbotfailed", err)
+	}
+	if w.logins != 1 || w.editRequests != 1 {
+		t.Errorf("got %d logins and %d edit requests, want 1 and 1", w.logins, w.editRequests)
+	}
+}
+
+func TestEditBadToken(t *testing.T) {
+	w, s := newFakeWiki(t)
+	if err := s.Login("Bot@Test", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	w.badTokens = 1
+
+	if _, err := s.EditRevision("Fire Hat", "new text", "test", 100); err != nil {
+		t.Fatalf("EditRevision: %v", err)
+	}
+	if w.edits != 1 {
+		t.Errorf("edits = %d, want 1", w.edits)
+	}
+}
+
+func TestEditConflict(t *testing.T) {
+	_, s := newFakeWiki(t)
+	if err := s.Login("Bot@Test", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.EditRevision("Fire Hat", "new text", "test", 99); !errors.Is(err, ErrEditConflict) {
+		t.Fatalf("EditRevision with an old base: got %v, want ErrEditConflict", err)
+	}
+}
@@ -0,0 +1,248 @@
+package wizlib
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// EffectKind identifies what a spell effect does.
+type EffectKind string
+
+const (
+	EffectDamage         EffectKind = "damage"
+	EffectDamageOverTime EffectKind = "damage_over_time"
+	EffectHeal           EffectKind = "heal"
+	EffectHealOverTime   EffectKind = "heal_over_time"
+	EffectBlade          EffectKind = "blade"
+	EffectTrap           EffectKind = "trap"
+	EffectShield         EffectKind = "shield"
+	EffectWeakness       EffectKind = "weakness"
+	EffectStun           EffectKind = "stun"
+	// EffectText is used for descriptions the parser does not understand; Text holds them verbatim.
+	EffectText EffectKind = "text"
+)
+
+// SpellEffect represents a single effect parsed from a spell description.
+type SpellEffect struct {
+	Kind    EffectKind `json:"kind"`
+	School  string     `json:"school,omitempty"`
+	Min     int        `json:"min,omitempty"`
+	Max     int        `json:"max,omitempty"`
+	Percent int        `json:"percent,omitempty"`
+	Target  string     `json:"target,omitempty"`
+	Rounds  int        `json:"rounds,omitempty"`
+	Text    string     `json:"text"`
+}
+
+// Spell represents a spell decoded from a spell infobox.
+type Spell struct {
+	Name          string            `json:"name"`
+	School        string            `json:"school"`
+	PipCost       int               `json:"pip_cost"`
+	XPips         bool              `json:"x_pips,omitempty"`
+	ShadowPipCost int               `json:"shadow_pip_cost,omitempty"`
+	Accuracy      int               `json:"accuracy"`
+	Type          string            `json:"type"`
+	Description   string            `json:"description"`
+	Effects       []SpellEffect     `json:"effects"`
+	Infobox       map[string]string `json:"infobox"`
+}
+
+// spellInfoboxNames lists the template names used for spell pages.
+var spellInfoboxNames = []string{"SpellInfobox", "Spell Infobox", "Infobox Spell", "CardInfobox"}
+
+// GetSpell retrieves a spell page and decodes its infobox.
+func (s *WikiService) GetSpell(title string) (*Spell, error) {
+	t, wiki, err := s.findInfobox(title, "spell")
+	if err != nil {
+		return nil, err
+	}
+	return DecodeSpell(wiki.Parse.Title, t), nil
+}
+
+// DecodeSpell builds a Spell from a spell infobox template.
+func DecodeSpell(title string, t Template) *Spell {
+	f := newInfoboxFields(t)
+
+	spell := &Spell{
+		Name:        f.text("name"),
+		School:      parseSchool(f.raw("school")),
+		Accuracy:    f.int("accuracy", "acc"),
+		Type:        f.text("type", "spelltype", "kind", "category"),
+		Description: f.text("description", "desc", "effect", "effects", "spelleffect"),
+		Infobox:     t.Map(),
+	}
+	if spell.Name == "" {
+		spell.Name = stripNamespace(title)
+	}
+
+	pips := f.raw("pips", "pipcost", "cost", "pip")
+	spell.XPips = strings.EqualFold(strings.TrimSpace(plainValue(pips)), "x")
+	spell.PipCost, _ = parseInt(plainValue(pips))
+	spell.ShadowPipCost = f.int("shadowpips", "shadowpip", "shadowpipcost", "shadow")
+	if spell.ShadowPipCost == 0 {
+		// Shadow pips are often written as shadow icons next to the regular pip cost.
+		spell.ShadowPipCost = strings.Count(strings.ToLower(pips), "shadow")
+	}
+
+	spell.Effects = ParseSpellEffects(spell.Description)
+
+	return spell
+}
+
+var (
+	clauseSplitRegex = regexp.MustCompile(`(?i)\.\s+|;\s*|\n|,?\s+then\s+`)
+	andNumberRegex   = regexp.MustCompile(`(?i)\s+and\s+([+-]?\d)`)
+	rangeRegex       = regexp.MustCompile(`(\d[\d,]*)(?:\s*[-–]\s*(\d[\d,]*))?`)
+	roundsRegex      = regexp.MustCompile(`(?i)(?:over|for)\s+(\d+)\s+rounds?`)
+	percentRegex     = regexp.MustCompile(`([+-])\s*(\d+)\s*%`)
+	damageRegex      = regexp.MustCompile(`(?i)^(?:deals?\s+)?(\d[\d,]*(?:\s*[-–]\s*\d[\d,]*)?)\s+(?:(\w+)\s+)?damage`)
+	healRegex        = regexp.MustCompile(`(?i)^(?:heals?\s+)?(?:target\s+|self\s+|all\s+allies\s+)?(?:for\s+)?(\d[\d,]*(?:\s*[-–]\s*\d[\d,]*)?)\s+(?:health|hp)`)
+	stunRegex        = regexp.MustCompile(`(?i)\bstuns?\b`)
+)
+
+// ParseSpellEffects parses a spell description such as "Deals 425-505 Fire damage to all enemies" into effects.
+// Clauses that cannot be understood are kept verbatim as EffectText.
+func ParseSpellEffects(description string) []SpellEffect {
+	var effects []SpellEffect
+	verb := ""
+
+	for _, clause := range splitClauses(description) {
+		clause = strings.TrimSpace(strings.TrimRight(clause, "."))
+		if clause == "" {
+			continue
+		}
+
+		// "Deals 100 Fire damage and 300 Fire damage over 3 rounds" carries the verb to the second clause.
+		lower := strings.ToLower(clause)
+		if strings.HasPrefix(lower, "deal") || strings.HasPrefix(lower, "heal") {
+			verb = strings.Fields(clause)[0]
+		} else if verb != "" && clause[0] >= '0' && clause[0] <= '9' {
+			clause = verb + " " + clause
+		}
+
+		effects = append(effects, parseEffect(clause))
+	}
+
+	return effects
+}
+
+// splitClauses splits a description into clauses, also splitting on "and" when a new amount follows.
+func splitClauses(description string) []string {
+	var clauses []string
+	for _, part := range clauseSplitRegex.Split(description, -1) {
+		for {
+			loc := andNumberRegex.FindStringSubmatchIndex(part)
+			if loc == nil {
+				break
+			}
+			clauses = append(clauses, part[:loc[0]])
+			part = part[loc[2]:]
+		}
+		clauses = append(clauses, part)
+	}
+	return clauses
+}
+
+// parseEffect parses a single clause of a spell description.
+func parseEffect(clause string) SpellEffect {
+	effect := SpellEffect{Kind: EffectText, Text: clause, Target: parseTarget(clause)}
+	lower := strings.ToLower(clause)
+
+	if m := roundsRegex.FindStringSubmatch(clause); m != nil {
+		effect.Rounds, _ = strconv.Atoi(m[1])
+	}
+
+	switch {
+	case damageRegex.MatchString(clause):
+		m := damageRegex.FindStringSubmatch(clause)
+		effect.Min, effect.Max = parseRange(m[1])
+		effect.School = parseSchool(m[2])
+		if !isSchool(effect.School) {
+			effect.School = ""
+		}
+		effect.Kind = EffectDamage
+		if effect.Rounds > 0 {
+			effect.Kind = EffectDamageOverTime
+		}
+	case healRegex.MatchString(clause):
+		m := healRegex.FindStringSubmatch(clause)
+		effect.Min, effect.Max = parseRange(m[1])
+		effect.Kind = EffectHeal
+		if effect.Rounds > 0 {
+			effect.Kind = EffectHealOverTime
+		}
+	case percentRegex.MatchString(clause):
+		m := percentRegex.FindStringSubmatch(clause)
+		effect.Percent, _ = strconv.Atoi(m[2])
+		effect.School = schoolIn(clause)
+		incoming := strings.Contains(lower, "incoming") || strings.Contains(lower, "receive") ||
+			strings.Contains(lower, "on target") || strings.Contains(lower, "enemy") || strings.Contains(lower, "enemies")
+		switch {
+		case m[1] == "+" && incoming:
+			effect.Kind = EffectTrap
+		case m[1] == "+":
+			effect.Kind = EffectBlade
+		case incoming:
+			effect.Kind = EffectShield
+		default:
+			effect.Kind = EffectWeakness
+		}
+		if m[1] == "-" {
+			effect.Percent = -effect.Percent
+		}
+	case stunRegex.MatchString(clause):
+		effect.Kind = EffectStun
+		if effect.Rounds == 0 {
+			effect.Rounds = 1
+		}
+	}
+
+	return effect
+}
+
+// parseRange parses "425-505" or "300" into a minimum and maximum.
+func parseRange(s string) (int, int) {
+	m := rangeRegex.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0
+	}
+	min, _ := strconv.Atoi(strings.ReplaceAll(m[1], ",", ""))
+	max := min
+	if m[2] != "" {
+		max, _ = strconv.Atoi(strings.ReplaceAll(m[2], ",", ""))
+	}
+	return min, max
+}
+
+// parseTarget returns who an effect applies to, as written in the clause.
+func parseTarget(clause string) string {
+	lower := strings.ToLower(clause)
+	for _, target := range []string{"all enemies", "all allies", "all friends", "target enemy", "target ally", "target friend", "self", "caster", "target"} {
+		if strings.Contains(lower, target) {
+			return target
+		}
+	}
+	return ""
+}
+
+// schoolIn returns the first school named in the text, or "" if none is.
+func schoolIn(text string) string {
+	for _, word := range strings.Fields(text) {
+		if school := parseSchool(strings.Trim(word, ".,:;()")); isSchool(school) {
+			return school
+		}
+	}
+	return ""
+}
+
+// isSchool reports whether s is one of the canonical school names.
+func isSchool(s string) bool {
+	for _, school := range Schools {
+		if s == school {
+			return true
+		}
+	}
+	return false
+}
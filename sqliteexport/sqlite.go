@@ -0,0 +1,170 @@
+// Package sqliteexport writes wizlib exports to a SQLite database. It lives in its own package so
+// that only programs exporting to SQLite depend on the SQLite engine.
+package sqliteexport
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/astridalia/wizlib"
+	_ "modernc.org/sqlite"
+)
+
+// Writer is a wizlib.EntityWriter that writes records to a SQLite database with one table per
+// entity, such as "items". Each table has a "title" primary key, a "_record" column with the full
+// record as JSON, and one column per flattened key; columns are added as new keys appear.
+// Rewriting a title replaces its row.
+type Writer struct {
+	DB      *sql.DB
+	Flatten wizlib.FlattenOptions
+
+	tx *sql.Tx
+	// columns holds the committed columns of each table, and txColumns those as seen by the open transaction.
+	columns   map[string]map[string]bool
+	txColumns map[string]map[string]bool
+}
+
+// NewWriter opens or creates the database at path.
+// The raw infobox is left out of the columns by default, as its keys vary from page to page; it remains in "_record".
+func NewWriter(path string) (*Writer, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec("PRAGMA journal_mode = WAL"); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	flatten := wizlib.DefaultFlattenOptions
+	flatten.Omit = []string{"infobox"}
+
+	return &Writer{DB: db, Flatten: flatten, columns: make(map[string]map[string]bool)}, nil
+}
+
+// Write inserts or replaces the record's row.
+func (w *Writer) Write(entity string, record map[string]interface{}) error {
+	if w.tx == nil {
+		tx, err := w.DB.Begin()
+		if err != nil {
+			return err
+		}
+		w.tx = tx
+		w.txColumns = make(map[string]map[string]bool)
+	}
+
+	columns := w.Flatten.Flatten(record)
+	delete(columns, "title")
+	names := make([]string, 0, len(columns))
+	for name := range columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if err := w.ensureTable(entity, names); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	quoted := []string{`"title"`, `"_record"`}
+	args := []interface{}{record["title"], string(data)}
+	for _, name := range names {
+		quoted = append(quoted, sqlQuote(name))
+		args = append(args, columns[name])
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(quoted)), ", ")
+
+	_, err = w.tx.Exec(fmt.Sprintf("INSERT OR REPLACE INTO %s (%s) VALUES (%s)",
+		sqlQuote(entity), strings.Join(quoted, ", "), placeholders), args...)
+	return err
+}
+
+// Flush commits the records written since the last flush. Tables and columns created in the
+// transaction are only remembered once it is committed.
+func (w *Writer) Flush() error {
+	if w.tx == nil {
+		return nil
+	}
+	err := w.tx.Commit()
+	if err == nil {
+		for entity, columns := range w.txColumns {
+			w.columns[entity] = columns
+		}
+	}
+	w.tx, w.txColumns = nil, nil
+	return err
+}
+
+// Close commits pending records and closes the database.
+func (w *Writer) Close() error {
+	err := w.Flush()
+	if cerr := w.DB.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// ensureTable creates the entity's table and adds any missing columns within the open transaction.
+func (w *Writer) ensureTable(entity string, names []string) error {
+	known, ok := w.txColumns[entity]
+	if !ok && w.columns[entity] != nil {
+		known = make(map[string]bool, len(w.columns[entity]))
+		for name := range w.columns[entity] {
+			known[name] = true
+		}
+		w.txColumns[entity] = known
+	}
+	if known == nil {
+		if _, err := w.tx.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s ("title" TEXT PRIMARY KEY, "_record" TEXT)`, sqlQuote(entity))); err != nil {
+			return err
+		}
+
+		rows, err := w.tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", sqlQuote(entity)))
+		if err != nil {
+			return err
+		}
+		known = make(map[string]bool)
+		for rows.Next() {
+			var (
+				cid, notNull, pk int
+				name, typ        string
+				dflt             sql.NullString
+			)
+			if err := rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk); err != nil {
+				rows.Close()
+				return err
+			}
+			known[strings.ToLower(name)] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		w.txColumns[entity] = known
+	}
+
+	for _, name := range names {
+		// SQLite column names are case-insensitive.
+		if known[strings.ToLower(name)] {
+			continue
+		}
+		if _, err := w.tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", sqlQuote(entity), sqlQuote(name))); err != nil {
+			return err
+		}
+		known[strings.ToLower(name)] = true
+	}
+
+	return nil
+}
+
+// sqlQuote quotes an SQL identifier.
+func sqlQuote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
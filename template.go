@@ -0,0 +1,244 @@
+package wizlib
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// TemplateParam represents a single parameter of a template call.
+// Positional parameters are keyed "1", "2", ... as in MediaWiki.
+// ValueStart and ValueEnd are the byte offsets of the trimmed value in the parsed wikitext.
+type TemplateParam struct {
+	Key        string `json:"key"`
+	Value      string `json:"value"`
+	Named      bool   `json:"named"`
+	ValueStart int    `json:"value_start"`
+	ValueEnd   int    `json:"value_end"`
+}
+
+// Template represents a template call such as {{ItemInfobox|school=Fire}} found in wikitext.
+// Start and End are the byte offsets of the call, including its braces.
+type Template struct {
+	Name   string          `json:"name"`
+	Params []TemplateParam `json:"params"`
+	Start  int             `json:"start"`
+	End    int             `json:"end"`
+}
+
+// Get returns the trimmed value of a parameter and whether it is present.
+func (t Template) Get(key string) (string, bool) {
+	for i := len(t.Params) - 1; i >= 0; i-- {
+		if t.Params[i].Key == key {
+			return t.Params[i].Value, true
+		}
+	}
+	return "", false
+}
+
+// Positional returns the values of the positional parameters in order.
+func (t Template) Positional() []string {
+	var values []string
+	for _, p := range t.Params {
+		if !p.Named {
+			values = append(values, p.Value)
+		}
+	}
+	return values
+}
+
+// Map returns the non-empty parameters of the template keyed by name.
+func (t Template) Map() map[string]string {
+	data := make(map[string]string)
+	for _, p := range t.Params {
+		if p.Value != "" {
+			data[p.Key] = p.Value
+		}
+	}
+	return data
+}
+
+// ParseTemplates returns the top-level template calls of the wikitext in order of appearance.
+// Templates nested in parameter values are left as raw text in those values.
+func ParseTemplates(wikiText string) []Template {
+	var templates []Template
+
+	for i := 0; i+1 < len(wikiText); {
+		if wikiText[i] != '{' || wikiText[i+1] != '{' {
+			i++
+			continue
+		}
+
+		end := matchBraces(wikiText, i)
+		if end < 0 {
+			// An unclosed "{{" is plain text; later templates are still parsed.
+			i += 2
+			continue
+		}
+
+		templates = append(templates, parseTemplate(wikiText[i:end], i))
+		i = end
+	}
+
+	return templates
+}
+
+// FindTemplate returns the first top-level template whose name matches one of names, ignoring case.
+func FindTemplate(wikiText string, names ...string) (Template, bool) {
+	for _, t := range ParseTemplates(wikiText) {
+		for _, name := range names {
+			if strings.EqualFold(normalizeTitle(t.Name), normalizeTitle(name)) {
+				return t, true
+			}
+		}
+	}
+	return Template{}, false
+}
+
+// parseTemplate parses a single "{{...}}" call located at offset.
+func parseTemplate(raw string, offset int) Template {
+	parts := splitTopLevel(raw[2:len(raw)-2], '|')
+
+	t := Template{
+		Name:  strings.TrimSpace(parts[0]),
+		Start: offset,
+		End:   offset + len(raw),
+	}
+
+	position := 0
+	at := offset + 2 + len(parts[0]) + 1
+	for _, part := range parts[1:] {
+		partStart := at
+		at += len(part) + 1
+
+		if eq := indexTopLevel(part, '='); eq >= 0 {
+			start, end := trimmedSpan(part, eq+1)
+			t.Params = append(t.Params, TemplateParam{
+				Key:        strings.TrimSpace(part[:eq]),
+				Value:      part[start:end],
+				Named:      true,
+				ValueStart: partStart + start,
+				ValueEnd:   partStart + end,
+			})
+			continue
+		}
+
+		position++
+		start, end := trimmedSpan(part, 0)
+		t.Params = append(t.Params, TemplateParam{
+			Key:        strconv.Itoa(position),
+			Value:      part[start:end],
+			ValueStart: partStart + start,
+			ValueEnd:   partStart + end,
+		})
+	}
+
+	return t
+}
+
+// trimmedSpan returns the bounds of s[from:] without its surrounding whitespace.
+func trimmedSpan(s string, from int) (int, int) {
+	value := s[from:]
+	start := from + len(value) - len(strings.TrimLeftFunc(value, unicode.IsSpace))
+	end := from + len(strings.TrimRightFunc(value, unicode.IsSpace))
+	if end < start {
+		end = start
+	}
+	return start, end
+}
+
+// matchBraces returns the offset just past the "}}" closing the "{{" at start, or -1.
+func matchBraces(s string, start int) int {
+	depth := 0
+	for i := start; i+1 < len(s); {
+		switch {
+		case s[i] == '{' && s[i+1] == '{':
+			depth++
+			i += 2
+		case s[i] == '}' && s[i+1] == '}':
+			depth--
+			i += 2
+			if depth == 0 {
+				return i
+			}
+		default:
+			i++
+		}
+	}
+	return -1
+}
+
+// splitTopLevel splits s on sep, ignoring separators nested in templates or links.
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	last := 0
+	for {
+		i := indexTopLevel(s[last:], sep)
+		if i < 0 {
+			return append(parts, s[last:])
+		}
+		parts = append(parts, s[last:last+i])
+		last += i + 1
+	}
+}
+
+// indexTopLevel returns the index of the first sep in s that is not nested in a template or link.
+func indexTopLevel(s string, sep byte) int {
+	braces, brackets := 0, 0
+	for i := 0; i < len(s); i++ {
+		if i+1 < len(s) {
+			switch s[i : i+2] {
+			case "{{":
+				braces++
+				i++
+				continue
+			case "}}":
+				if braces > 0 {
+					braces--
+				}
+				i++
+				continue
+			case "[[":
+				brackets++
+				i++
+				continue
+			case "]]":
+				if brackets > 0 {
+					brackets--
+				}
+				i++
+				continue
+			}
+		}
+		if s[i] == sep && braces == 0 && brackets == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// Section represents a headed section of wikitext; text before the first heading has level 0.
+type Section struct {
+	Level int    `json:"level"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// ParseSections splits wikitext at its headings.
+func ParseSections(wikiText string) []Section {
+	sections := []Section{{}}
+	var body []string
+
+	for _, line := range strings.Split(wikiText, "\n") {
+		if m := headingRegex.FindStringSubmatch(line); m != nil {
+			sections[len(sections)-1].Body = strings.Join(body, "\n")
+			sections = append(sections, Section{Level: len(m[1]), Title: plainValue(m[2])})
+			body = nil
+			continue
+		}
+		body = append(body, line)
+	}
+	sections[len(sections)-1].Body = strings.Join(body, "\n")
+
+	return sections
+}
@@ -0,0 +1,15 @@
+package wizlib
+
+import "testing"
+
+func TestParseTemplatesSkipsUnclosed(t *testing.T) {
+	templates := ParseTemplates("{{Broken|a\n{{Icon|Fire}} and {{Icon|Ice}}")
+	if len(templates) != 2 {
+		t.Fatalf("got %d templates, want 2", len(templates))
+	}
+	for i, want := range []string{"Fire", "Ice"} {
+		if got, _ := templates[i].Get("1"); got != want {
+			t.Errorf("template %d: got %q, want %q", i, got, want)
+		}
+	}
+}
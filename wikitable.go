@@ -0,0 +1,395 @@
+package wizlib
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// TableCell represents a single cell of a parsed table.
+// Cells spanning several rows or columns are repeated in every position they cover.
+type TableCell struct {
+	Text   string `json:"text"`
+	Raw    string `json:"raw"`
+	Header bool   `json:"header"`
+}
+
+// WikiTable represents a table parsed from wikitext or rendered HTML.
+type WikiTable struct {
+	Caption string        `json:"caption,omitempty"`
+	Headers []string      `json:"headers"`
+	Rows    [][]TableCell `json:"rows"`
+
+	// numbered is set when the table has no header row and Headers are column numbers.
+	numbered bool
+}
+
+// Records returns the body rows of the table as maps keyed by header.
+func (t WikiTable) Records() []map[string]string {
+	records := make([]map[string]string, 0, len(t.Rows))
+	for _, row := range t.Rows {
+		record := make(map[string]string, len(row))
+		for i, cell := range row {
+			if i < len(t.Headers) {
+				record[t.Headers[i]] = cell.Text
+			}
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// Column returns the index of the first header matching one of names, ignoring case, or -1.
+func (t WikiTable) Column(names ...string) int {
+	for i, header := range t.Headers {
+		for _, name := range names {
+			if strings.EqualFold(header, name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// GetTables retrieves a page and parses the wikitables in its wikitext.
+func (s *WikiService) GetTables(pageName string) ([]WikiTable, error) {
+	wiki, err := s.GetWikiText(pageName)
+	if err != nil {
+		return nil, err
+	}
+	return ParseWikiTables(wiki.Parse.Content), nil
+}
+
+// tableCellRenderer renders cell contents, keeping the arguments of templates such as {{Icon|Fire}}.
+var tableCellRenderer = &Renderer{Templates: TemplateArgs}
+
+// ParseWikiTables parses every top-level {| ... |} table in the wikitext.
+// Nested tables are kept as raw text in the cell that contains them.
+func ParseWikiTables(wikiText string) []WikiTable {
+	var tables []WikiTable
+	for _, raw := range findWikiTables(wikiText) {
+		tables = append(tables, parseWikiTable(raw, tableCellRenderer.PlainText))
+	}
+	return tables
+}
+
+// ParseHTMLTables parses the selected <table> elements, for example doc.Find("table.wikitable")
+// on a document retrieved through a DocumentFetcher.
+func ParseHTMLTables(tables *goquery.Selection) []WikiTable {
+	var result []WikiTable
+	tables.Each(func(_ int, table *goquery.Selection) {
+		var rows [][]rawTableCell
+		table.Find("tr").Each(func(_ int, tr *goquery.Selection) {
+			// Skip rows that belong to nested tables.
+			if tr.Closest("table").Get(0) != table.Get(0) {
+				return
+			}
+			var row []rawTableCell
+			tr.ChildrenFiltered("th, td").Each(func(_ int, cell *goquery.Selection) {
+				raw, _ := cell.Html()
+				row = append(row, rawTableCell{
+					TableCell: TableCell{
+						Text:   strings.Join(strings.Fields(cell.Text()), " "),
+						Raw:    strings.TrimSpace(raw),
+						Header: goquery.NodeName(cell) == "th",
+					},
+					rowspan: spanAttribute(cell.AttrOr("rowspan", ""), maxRowspan),
+					colspan: spanAttribute(cell.AttrOr("colspan", ""), maxColspan),
+				})
+			})
+			rows = append(rows, row)
+		})
+
+		caption := strings.TrimSpace(table.ChildrenFiltered("caption").Text())
+		result = append(result, buildTable(caption, rows))
+	})
+	return result
+}
+
+// rawTableCell is a cell before row and column spans are expanded.
+type rawTableCell struct {
+	TableCell
+	rowspan, colspan int
+}
+
+// findWikiTables returns the raw text of each top-level table in the wikitext.
+func findWikiTables(wikiText string) []string {
+	var tables []string
+	var current []string
+	depth := 0
+
+	for _, line := range strings.Split(wikiText, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "{|"):
+			depth++
+		case depth == 0:
+			continue
+		}
+
+		current = append(current, line)
+		if strings.HasPrefix(trimmed, "|}") {
+			depth--
+			if depth == 0 {
+				tables = append(tables, strings.Join(current, "\n"))
+				current = nil
+			}
+		}
+	}
+
+	return tables
+}
+
+// parseWikiTable parses the raw text of a single table, using text to derive each cell's displayed text.
+func parseWikiTable(raw string, text func(string) string) WikiTable {
+	lines := strings.Split(raw, "\n")
+	var rows [][]rawTableCell
+	var caption string
+	var cell *rawTableCell
+	depth := 0
+
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimSpace(line)
+
+		// Lines of nested tables belong to the current cell, or to a new one when the
+		// table starts right after a row separator.
+		if depth > 0 || strings.HasPrefix(trimmed, "{|") {
+			if cell == nil {
+				if len(rows) == 0 {
+					rows = append(rows, nil)
+				}
+				rows[len(rows)-1] = append(rows[len(rows)-1], rawTableCell{rowspan: 1, colspan: 1})
+				last := rows[len(rows)-1]
+				cell = &last[len(last)-1]
+			}
+			if strings.HasPrefix(trimmed, "{|") {
+				depth++
+			} else if strings.HasPrefix(trimmed, "|}") {
+				depth--
+			}
+			cell.Raw += "\n" + line
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "|}"):
+			cell = nil
+		case strings.HasPrefix(trimmed, "|+"):
+			caption = strings.TrimSpace(splitCellAttributes(trimmed[2:]))
+			cell = nil
+		case strings.HasPrefix(trimmed, "|-"):
+			rows = append(rows, nil)
+			cell = nil
+		case strings.HasPrefix(trimmed, "!"), strings.HasPrefix(trimmed, "|"):
+			if len(rows) == 0 {
+				rows = append(rows, nil)
+			}
+			header := trimmed[0] == '!'
+			separators := []string{"||"}
+			if header {
+				separators = append(separators, "!!")
+			}
+			for _, content := range splitTableCells(trimmed[1:], separators) {
+				attributes := ""
+				if i := indexTopLevel(content, '|'); i >= 0 && !strings.Contains(content[:i], "[") {
+					attributes, content = content[:i], content[i+1:]
+				}
+				rows[len(rows)-1] = append(rows[len(rows)-1], rawTableCell{
+					TableCell: TableCell{Raw: strings.TrimSpace(content), Header: header},
+					rowspan:   spanAttribute(attributeValue(attributes, "rowspan"), maxRowspan),
+					colspan:   spanAttribute(attributeValue(attributes, "colspan"), maxColspan),
+				})
+			}
+			last := rows[len(rows)-1]
+			cell = &last[len(last)-1]
+		case cell != nil:
+			// Continuation of the previous cell on a new line.
+			cell.Raw += "\n" + line
+		}
+	}
+
+	for _, row := range rows {
+		for i := range row {
+			row[i].Raw = strings.TrimSpace(row[i].Raw)
+			row[i].Text = text(row[i].Raw)
+		}
+	}
+
+	return buildTable(caption, rows)
+}
+
+// buildTable expands row and column spans and splits the leading header rows from the body.
+func buildTable(caption string, rows [][]rawTableCell) WikiTable {
+	grid := expandSpans(rows)
+
+	headerRows := 0
+	for headerRows < len(grid) && isHeaderRow(grid[headerRows]) {
+		headerRows++
+	}
+
+	width := 0
+	for _, row := range grid {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+
+	table := WikiTable{Caption: caption, Rows: grid[headerRows:], numbered: headerRows == 0}
+	seen := make(map[string]int)
+	for col := 0; col < width; col++ {
+		// Grouped headers spanning several columns are combined with the header below them.
+		var parts []string
+		for _, row := range grid[:headerRows] {
+			if col < len(row) && row[col].Text != "" && (len(parts) == 0 || parts[len(parts)-1] != row[col].Text) {
+				parts = append(parts, row[col].Text)
+			}
+		}
+		name := strings.Join(parts, " ")
+		if name == "" {
+			name = strconv.Itoa(col + 1)
+		}
+		if seen[name]++; seen[name] > 1 {
+			name += " " + strconv.Itoa(seen[name])
+		}
+		table.Headers = append(table.Headers, name)
+	}
+
+	return table
+}
+
+// expandSpans lays out cells on a grid, copying cells into every row and column they span.
+func expandSpans(rows [][]rawTableCell) [][]TableCell {
+	var grid [][]TableCell
+	pending := make(map[int]rawTableCell)
+
+	for _, row := range rows {
+		if len(row) == 0 && len(pending) == 0 {
+			continue
+		}
+
+		var out []TableCell
+		col := 0
+		fill := func() {
+			for {
+				carried, ok := pending[col]
+				if !ok {
+					return
+				}
+				out = append(out, carried.TableCell)
+				if carried.rowspan--; carried.rowspan <= 1 {
+					delete(pending, col)
+				} else {
+					pending[col] = carried
+				}
+				col++
+			}
+		}
+
+		for _, cell := range row {
+			fill()
+			for i := 0; i < cell.colspan; i++ {
+				out = append(out, cell.TableCell)
+				if cell.rowspan > 1 {
+					pending[col] = cell
+				}
+				col++
+			}
+		}
+		fill()
+
+		grid = append(grid, out)
+	}
+
+	return grid
+}
+
+// isHeaderRow reports whether every cell of the row is a header cell.
+func isHeaderRow(row []TableCell) bool {
+	if len(row) == 0 {
+		return false
+	}
+	for _, cell := range row {
+		if !cell.Header {
+			return false
+		}
+	}
+	return true
+}
+
+// splitTableCells splits a table line into cells on any of the separators, ignoring nested templates and links.
+func splitTableCells(line string, separators []string) []string {
+	var cells []string
+	braces, brackets, last := 0, 0, 0
+	for i := 0; i+1 < len(line); i++ {
+		switch line[i : i+2] {
+		case "{{":
+			braces++
+			i++
+			continue
+		case "}}":
+			braces--
+			i++
+			continue
+		case "[[":
+			brackets++
+			i++
+			continue
+		case "]]":
+			brackets--
+			i++
+			continue
+		}
+		if braces > 0 || brackets > 0 {
+			continue
+		}
+		for _, sep := range separators {
+			if line[i:i+2] == sep {
+				cells = append(cells, line[last:i])
+				last = i + 2
+				i++
+				break
+			}
+		}
+	}
+	return append(cells, line[last:])
+}
+
+// splitCellAttributes returns the content of a cell without its leading "attributes |" part.
+func splitCellAttributes(cell string) string {
+	if i := indexTopLevel(cell, '|'); i >= 0 && !strings.Contains(cell[:i], "[") {
+		return cell[i+1:]
+	}
+	return cell
+}
+
+var attributeRegex = regexp.MustCompile(`(?i)([a-z-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"']+))`)
+
+// attributeValue returns the value of an HTML attribute in a wikitext attribute list.
+func attributeValue(attributes, name string) string {
+	for _, m := range attributeRegex.FindAllStringSubmatch(attributes, -1) {
+		if strings.EqualFold(m[1], name) {
+			return m[2] + m[3] + m[4]
+		}
+	}
+	return ""
+}
+
+// Spans are clamped to the limits browsers apply, so that a single cell cannot make a table huge.
+const (
+	maxColspan = 1000
+	maxRowspan = 65534
+)
+
+// spanAttribute parses a rowspan or colspan value, defaulting to 1 and clamped to max.
+func spanAttribute(value string, max int) int {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 1 {
+		return 1
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
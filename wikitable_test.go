@@ -0,0 +1,37 @@
+package wizlib
+
+import "testing"
+
+func TestParseWikiTablesClampsSpans(t *testing.T) {
+	tables := ParseWikiTables("{|\n! Name !! Level\n|-\n| colspan=1000000 | Fire Dragon\n|}")
+	if len(tables) != 1 || len(tables[0].Rows) != 1 {
+		t.Fatalf("got %+v, want one table with one row", tables)
+	}
+	if n := len(tables[0].Rows[0]); n != maxColspan {
+		t.Errorf("row has %d cells, want %d", n, maxColspan)
+	}
+}
+
+func TestParseWikiTablesNestedAfterRow(t *testing.T) {
+	raw := "{|\n! Name !! Drops\n|-\n| Ghost\n|-\n{|\n| inner || table\n|}\n|}"
+	tables := ParseWikiTables(raw)
+	if len(tables) != 1 {
+		t.Fatalf("got %d tables, want 1", len(tables))
+	}
+	rows := tables[0].Rows
+	if len(rows) != 2 || len(rows[1]) != 1 {
+		t.Fatalf("rows = %+v, want the nested table in a single cell of the second row", rows)
+	}
+}
+
+func TestParseWikiTablesNumberedHeaders(t *testing.T) {
+	// A real header named "1" must not be mistaken for a generated one.
+	tables := ParseWikiTables("{|\n! 1 !! 2\n|-\n| a || b\n|}")
+	if len(tables) != 1 || tables[0].numbered {
+		t.Fatalf("got %+v, want a table with a header row", tables)
+	}
+	tables = ParseWikiTables("{|\n| a || b\n|}")
+	if len(tables) != 1 || !tables[0].numbered {
+		t.Fatalf("got %+v, want generated headers", tables)
+	}
+}